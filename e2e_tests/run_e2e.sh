@@ -72,16 +72,19 @@ case "${MODE}" in
     # Check inly part of metrics.
     # No full backup with this prefix.
         declare -a REGEX_LIST=(
-    '^medusa_backup_info{.*,backup_type="differential",prefix="only_diff_prefix",.*} 1$|1'
-    '^medusa_backup_info{.*,backup_type="full",prefix="only_diff_prefix",.*}|0'
-    '^medusa_backup_last_duration_seconds{backup_type="differential"}|1'
-    '^medusa_backup_last_duration_seconds{backup_type="full"}|0'
-    '^medusa_backup_last_objects{backup_type="differential"}|1'
-    '^medusa_backup_last_objects{backup_type="full"}|0'
-    '^medusa_backup_last_size_bytes{backup_type="differential"}|1'
-    '^medusa_backup_last_size_bytes{backup_type="full"}|0'
-    '^medusa_backup_status{.*,backup_type="differential"} 0$|1'
-    '^medusa_exporter_status{prefix="only_diff_prefix"} 1$|1'
+    '^medusa_backup_info{.*,backup_type="differential",cluster="default",prefix="only_diff_prefix",.*} 1$|1'
+    '^medusa_backup_info{.*,backup_type="full",cluster="default",prefix="only_diff_prefix",.*}|0'
+    '^medusa_backup_inventory_backups{backup_type="differential",cluster="default",prefix="only_diff_prefix"} 1$|1'
+    '^medusa_backup_inventory_backups{backup_type="full",cluster="default",prefix="only_diff_prefix"} 0$|1'
+    '^medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="full",cluster="default",prefix="only_diff_prefix"}|0'
+    '^medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="only_diff_prefix"}|1'
+    '^medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="only_diff_prefix"}|0'
+    '^medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="only_diff_prefix"}|1'
+    '^medusa_backup_last_objects{backup_type="full",cluster="default",prefix="only_diff_prefix"}|0'
+    '^medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="only_diff_prefix"}|1'
+    '^medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="only_diff_prefix"}|0'
+    '^medusa_backup_status{.*,backup_type="differential",cluster="default",prefix="only_diff_prefix"} 0$|1'
+    '^medusa_exporter_status{cluster="default",prefix="only_diff_prefix",reason="none"} 1$|1'
     '^medusa_node_backup_info{.*,backup_type="differential",.*} 1$|1'
     '^medusa_node_backup_status{.*,backup_type="differential",.*} 0$|1'
         )
@@ -90,48 +93,78 @@ case "${MODE}" in
     # Check inly part of metrics.
     # No differential backup with this prefix.
         declare -a REGEX_LIST=(
-    '^medusa_backup_info{.*,backup_type="differential",prefix="only_full_prefix",.*}|0'
-    '^medusa_backup_info{.*,backup_type="full",prefix="only_full_prefix",.*} 1$|1'
-    '^medusa_backup_last_duration_seconds{backup_type="differential"}|0'
-    '^medusa_backup_last_duration_seconds{backup_type="full"}|1'
-    '^medusa_backup_last_objects{backup_type="differential"}|0'
-    '^medusa_backup_last_objects{backup_type="full"}|1'
-    '^medusa_backup_last_size_bytes{backup_type="differential"}|0'
-    '^medusa_backup_last_size_bytes{backup_type="full"}|1'
-    '^medusa_backup_status{.*,backup_type="full"} 0$|1'
-    '^medusa_exporter_status{prefix="only_full_prefix"} 1$|1'
+    '^medusa_backup_info{.*,backup_type="differential",cluster="default",prefix="only_full_prefix",.*}|0'
+    '^medusa_backup_info{.*,backup_type="full",cluster="default",prefix="only_full_prefix",.*} 1$|1'
+    '^medusa_backup_inventory_backups{backup_type="differential",cluster="default",prefix="only_full_prefix"} 0$|1'
+    '^medusa_backup_inventory_backups{backup_type="full",cluster="default",prefix="only_full_prefix"} 1$|1'
+    '^medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="differential",cluster="default",prefix="only_full_prefix"}|0'
+    '^medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="only_full_prefix"}|0'
+    '^medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="only_full_prefix"}|1'
+    '^medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="only_full_prefix"}|0'
+    '^medusa_backup_last_objects{backup_type="full",cluster="default",prefix="only_full_prefix"}|1'
+    '^medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="only_full_prefix"}|0'
+    '^medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="only_full_prefix"}|1'
+    '^medusa_backup_status{.*,backup_type="full",cluster="default",prefix="only_full_prefix"} 0$|1'
+    '^medusa_exporter_status{cluster="default",prefix="only_full_prefix",reason="none"} 1$|1'
     '^medusa_node_backup_info{.*,backup_type="full",.*} 1$|1'
     '^medusa_node_backup_status{.*,backup_type="full",.*} 0$|1'
         )
         ;;
     *)
         declare -a REGEX_LIST=(
-    '^medusa_backup_completed_nodes{.*,backup_type="differential"} 1$|1'
-    '^medusa_backup_completed_nodes{.*,backup_type="full"} 1$|1'
+    '^medusa_backup_completed_nodes{.*,backup_type="differential",cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_backup_completed_nodes{.*,backup_type="full",cluster="default",prefix="no-prefix"} 1$|1'
     '^medusa_backup_duration_seconds{.*,backup_type="differential",.*}|1'
     '^medusa_backup_duration_seconds{.*,backup_type="full",.*}|1'
-    '^medusa_backup_incomplete_nodes{.*,backup_type="differential"} 0$|1'
-    '^medusa_backup_incomplete_nodes{.*,backup_type="full"} 0$|1'
-    '^medusa_backup_info{.*,backup_type="differential",prefix="no-prefix",.*} 1$|1'
-    '^medusa_backup_info{.*,backup_type="full",prefix="no-prefix",.*} 1$|1'
-    '^medusa_backup_last_duration_seconds{backup_type="differential"}|1'
-    '^medusa_backup_last_duration_seconds{backup_type="full"}|1'
-    '^medusa_backup_last_objects{backup_type="differential"}|1'
-    '^medusa_backup_last_objects{backup_type="full"}|1'
-    '^medusa_backup_last_size_bytes{backup_type="differential"}|1'
-    '^medusa_backup_last_size_bytes{backup_type="full"}|1'
-    '^medusa_backup_missing_nodes{.*,backup_type="differential"} 0$|1'
-    '^medusa_backup_missing_nodes{.*,backup_type="full"} 0$|1'
-    '^medusa_backup_objects{.*,backup_type="differential"}|1'
-    '^medusa_backup_objects{.*,backup_type="full"}|1'
-    '^medusa_backup_since_last_completion_seconds{backup_type="differential"}|1'
-    '^medusa_backup_since_last_completion_seconds{backup_type="full"}|1'
-    '^medusa_backup_size_bytes{.*,backup_type="differential"}|1'
-    '^medusa_backup_size_bytes{.*,backup_type="full"}|1'
-    '^medusa_backup_status{.*,backup_type="differential"} 0$|1'
-    '^medusa_backup_status{.*,backup_type="full"} 0$|1'
+    '^medusa_backup_incomplete_nodes{.*,backup_type="differential",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_incomplete_nodes{.*,backup_type="full",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_info{.*,backup_type="differential",cluster="default",prefix="no-prefix",.*} 1$|1'
+    '^medusa_backup_info{.*,backup_type="full",cluster="default",prefix="no-prefix",.*} 1$|1'
+    '^medusa_backup_inventory_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_backup_inventory_backups{backup_type="full",cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_backup_inventory_complete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_backup_inventory_complete_backups{backup_type="full",cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_backup_inventory_incomplete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_inventory_incomplete_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_inventory_newest_start_timestamp_seconds{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_newest_start_timestamp_seconds{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_referenced_objects{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_referenced_objects{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_referenced_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_inventory_referenced_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_objects{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_missing_nodes{.*,backup_type="differential",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_missing_nodes{.*,backup_type="full",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_objects{.*,backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_objects{.*,backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_since_last_completion_seconds{backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_since_last_completion_seconds{backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_size_bytes{.*,backup_type="differential",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_size_bytes{.*,backup_type="full",cluster="default",prefix="no-prefix"}|1'
+    '^medusa_backup_status{.*,backup_type="differential",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_backup_status{.*,backup_type="full",cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_exporter_backups_parsed{cluster="default",prefix="no-prefix"} 2$|1'
     '^medusa_exporter_build_info{.*} 1$|1'
-    '^medusa_exporter_status{prefix="no-prefix"} 1$|1'
+    '^medusa_exporter_collection_duration_seconds{cluster="default",prefix="no-prefix"}|1'
+    '^medusa_exporter_command_duration_seconds{cluster="default",prefix="no-prefix"}|1'
+    '^medusa_exporter_command_exit_code{cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_exporter_command_stdout_bytes{cluster="default",prefix="no-prefix"}|1'
+    '^medusa_exporter_consecutive_failures{cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_exporter_get_data_attempts_total{cluster="default",prefix="no-prefix"} 1$|1'
+    '^medusa_exporter_get_data_total{cluster="default",outcome="success",prefix="no-prefix"} 1$|1'
+    '^medusa_exporter_json_parse_errors_total{cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_exporter_last_success_timestamp_seconds{cluster="default",prefix="no-prefix"}|1'
+    '^medusa_exporter_next_collection_timestamp_seconds |1'
+    '^medusa_exporter_set_metric_errors_total{cluster="default",prefix="no-prefix"} 0$|1'
+    '^medusa_exporter_status{cluster="default",prefix="no-prefix",reason="none"} 1$|1'
+    '^medusa_exporter_timeouts_total{cluster="default",prefix="no-prefix"} 0$|1'
     '^medusa_node_backup_duration_seconds{.*,backup_type="differential",.*"}|1'
     '^medusa_node_backup_duration_seconds{.*,backup_type="full",.*}|1'
     '^medusa_node_backup_info{.*,backup_type="differential",.*} 1$|1'
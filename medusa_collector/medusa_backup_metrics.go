@@ -5,7 +5,6 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 const (
@@ -15,114 +14,138 @@ const (
 )
 
 var (
-	medusaBackupInfoMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaBackupInfoMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_info",
 		Help: "Backup info.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
 			"prefix",
 			"start_time",
 		})
-	medusaBackupStatusMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaBackupStatusMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_status",
 		Help: "Backup status.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaBackupDurationMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupDurationMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_duration_seconds",
 		Help: "Backup duration.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
+			"prefix",
 			"start_time",
 			"stop_time"})
-	medusaBackupDatabaseSizeMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaBackupDatabaseSizeMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_size_bytes",
 		Help: "Backup size.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaBackupObjectsMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupObjectsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_objects",
 		Help: "Number of objects in backup.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaBackupNodesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupNodesMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_completed_nodes",
 		Help: "Number of completed nodes in backup.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaBackupIncompleteNodesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupIncompleteNodesMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_incomplete_nodes",
 		Help: "Number of incomplete nodes in backup.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaBackupMissingNodesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupMissingNodesMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_missing_nodes",
 		Help: "Number of missing nodes in backup.",
 	},
 		[]string{
 			"backup_name",
-			"backup_type"})
-	medusaNodeBackupsInfosMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaNodeBackupsInfosMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_node_backup_info",
 		Help: "Node backup info.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
 			"node_fqdn",
 			"prefix",
 			"release_version",
 			"server_type",
 			"start_time"})
-	medusaNodeBackupsStatusMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaNodeBackupsStatusMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_node_backup_status",
 		Help: "Node backup status.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
-			"node_fqdn"})
-	medusaNodeBackupDurationMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"cluster",
+			"node_fqdn",
+			"prefix"})
+	medusaNodeBackupDurationMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_node_backup_duration_seconds",
 		Help: "Node backup duration.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
 			"node_fqdn",
+			"prefix",
 			"start_time",
 			"stop_time"})
-	medusaNodeBackupsSizeMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaNodeBackupsSizeMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_node_backup_size_bytes",
 		Help: "Node backup size.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
 			"node_fqdn",
+			"prefix",
 		})
-	medusaNodeBackupsObjectsMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaNodeBackupsObjectsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_node_backup_objects",
 		Help: "Number of objects in node backup.",
 	},
 		[]string{
 			"backup_name",
 			"backup_type",
+			"cluster",
 			"node_fqdn",
+			"prefix",
 		})
 )
 
@@ -140,10 +163,9 @@ var (
 //   - medusa_node_backup_duration_seconds
 //   - medusa_node_backup_size_bytes
 //   - medusa_node_backup_objects
-func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
-	if prefix == "" {
-		prefix = noPrefixLabel
-	}
+func getBackupMetrics(backupData backup, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
 	// Backup info.
 	//  1 - info about backup is exist.
 	setUpMetric(
@@ -154,6 +176,7 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
 		prefix,
 		time.Unix(backupData.Started, 0).Format(layout),
 	)
@@ -166,6 +189,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Backup duration.
 	backupDuration, backupStopTime := calculateDuration(backupData.Started, backupData.Finished)
@@ -177,6 +202,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 		time.Unix(backupData.Started, 0).Format(layout),
 		backupStopTime,
 	)
@@ -189,6 +216,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Backup objects.
 	setUpMetric(
@@ -199,6 +228,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Backup completed nodes.
 	setUpMetric(
@@ -209,6 +240,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Backup incomplete nodes.
 	setUpMetric(
@@ -219,6 +252,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Backup missing nodes.
 	setUpMetric(
@@ -229,6 +264,8 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 		logger,
 		backupData.Name,
 		backupData.BackupType,
+		cluster,
+		prefix,
 	)
 	// Node backup metrics.
 	// In this case, checking for a Finished field is unnecessary,
@@ -236,12 +273,17 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 	// The rest of the nodes should be IncompleteNodesList or MissingNodesList.
 	// However, there may be bugs in Medusa, so an additional check would not hurt.
 	for _, node := range backupData.Nodes {
+		status := getBackupStatusCode(node.Finished)
+		if node.finishedWithoutTime {
+			status = statusComplete
+		}
 		setNodeMetrics(
 			node,
 			backupData.Name,
 			backupData.BackupType,
+			cluster,
 			prefix,
-			getBackupStatusCode(node.Finished),
+			status,
 			setUpMetricValueFun,
 			logger,
 		)
@@ -253,6 +295,7 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 			node,
 			backupData.Name,
 			backupData.BackupType,
+			cluster,
 			prefix,
 			statusIncomplete,
 			setUpMetricValueFun,
@@ -273,6 +316,7 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 			},
 			backupData.Name,
 			backupData.BackupType,
+			cluster,
 			prefix,
 			statusMissing,
 			setUpMetricValueFun,
@@ -281,22 +325,6 @@ func getBackupMetrics(backupData backup, prefix string, setUpMetricValueFun setU
 	}
 }
 
-func resetBackupMetrics() {
-	medusaBackupInfoMetric.Reset()
-	medusaBackupStatusMetric.Reset()
-	medusaBackupDurationMetric.Reset()
-	medusaBackupDatabaseSizeMetric.Reset()
-	medusaBackupObjectsMetric.Reset()
-	medusaBackupNodesMetric.Reset()
-	medusaBackupIncompleteNodesMetric.Reset()
-	medusaBackupMissingNodesMetric.Reset()
-	medusaNodeBackupsInfosMetric.Reset()
-	medusaNodeBackupsStatusMetric.Reset()
-	medusaNodeBackupDurationMetric.Reset()
-	medusaNodeBackupsSizeMetric.Reset()
-	medusaNodeBackupsObjectsMetric.Reset()
-}
-
 func getBackupStatusCode(finished int64) float64 {
 	if finished > 0 {
 		return statusComplete
@@ -304,7 +332,7 @@ func getBackupStatusCode(finished int64) float64 {
 	return statusIncomplete
 }
 
-func setNodeMetrics(node node, backupName, backupType, prefix string, status float64, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+func setNodeMetrics(node node, backupName, backupType, cluster, prefix string, status float64, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
 	nodeStartTime := noneLabel
 	if node.Started > 0 {
 		nodeStartTime = time.Unix(node.Started, 0).Format(layout)
@@ -319,6 +347,7 @@ func setNodeMetrics(node node, backupName, backupType, prefix string, status flo
 		logger,
 		backupName,
 		backupType,
+		cluster,
 		node.FQDN,
 		prefix,
 		node.ReleaseVersion,
@@ -334,7 +363,9 @@ func setNodeMetrics(node node, backupName, backupType, prefix string, status flo
 		logger,
 		backupName,
 		backupType,
+		cluster,
 		node.FQDN,
+		prefix,
 	)
 	// Node backup duration.
 	nodeDuration, nodeStopTime := calculateDuration(node.Started, node.Finished)
@@ -346,7 +377,9 @@ func setNodeMetrics(node node, backupName, backupType, prefix string, status flo
 		logger,
 		backupName,
 		backupType,
+		cluster,
 		node.FQDN,
+		prefix,
 		nodeStartTime,
 		nodeStopTime,
 	)
@@ -359,7 +392,9 @@ func setNodeMetrics(node node, backupName, backupType, prefix string, status flo
 		logger,
 		backupName,
 		backupType,
+		cluster,
 		node.FQDN,
+		prefix,
 	)
 	// Node backup objects.
 	setUpMetric(
@@ -370,7 +405,9 @@ func setNodeMetrics(node node, backupName, backupType, prefix string, status flo
 		logger,
 		backupName,
 		backupType,
+		cluster,
 		node.FQDN,
+		prefix,
 	)
 }
 
@@ -11,6 +11,22 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+func resetBackupMetrics() {
+	medusaBackupInfoMetric.Reset()
+	medusaBackupStatusMetric.Reset()
+	medusaBackupDurationMetric.Reset()
+	medusaBackupDatabaseSizeMetric.Reset()
+	medusaBackupObjectsMetric.Reset()
+	medusaBackupNodesMetric.Reset()
+	medusaBackupIncompleteNodesMetric.Reset()
+	medusaBackupMissingNodesMetric.Reset()
+	medusaNodeBackupsInfosMetric.Reset()
+	medusaNodeBackupsStatusMetric.Reset()
+	medusaNodeBackupDurationMetric.Reset()
+	medusaNodeBackupsSizeMetric.Reset()
+	medusaNodeBackupsObjectsMetric.Reset()
+}
+
 func TestGetBackupMetrics(t *testing.T) {
 	type args struct {
 		backupData          backup
@@ -53,43 +69,43 @@ func TestGetBackupMetrics(t *testing.T) {
 				setUpMetricValue,
 				`# HELP medusa_backup_completed_nodes Number of completed nodes in backup.
 # TYPE medusa_backup_completed_nodes gauge
-medusa_backup_completed_nodes{backup_name="test_backup",backup_type="differential"} 1
+medusa_backup_completed_nodes{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 1
 # HELP medusa_backup_duration_seconds Backup duration.
 # TYPE medusa_backup_duration_seconds gauge
-medusa_backup_duration_seconds{backup_name="test_backup",backup_type="differential",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
+medusa_backup_duration_seconds{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
 # HELP medusa_backup_incomplete_nodes Number of incomplete nodes in backup.
 # TYPE medusa_backup_incomplete_nodes gauge
-medusa_backup_incomplete_nodes{backup_name="test_backup",backup_type="differential"} 0
+medusa_backup_incomplete_nodes{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 0
 # HELP medusa_backup_info Backup info.
 # TYPE medusa_backup_info gauge
-medusa_backup_info{backup_name="test_backup",backup_type="differential",prefix="no-prefix",start_time="2023-10-19 10:38:20"} 1
+medusa_backup_info{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix",start_time="2023-10-19 10:38:20"} 1
 # HELP medusa_backup_missing_nodes Number of missing nodes in backup.
 # TYPE medusa_backup_missing_nodes gauge
-medusa_backup_missing_nodes{backup_name="test_backup",backup_type="differential"} 0
+medusa_backup_missing_nodes{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 0
 # HELP medusa_backup_objects Number of objects in backup.
 # TYPE medusa_backup_objects gauge
-medusa_backup_objects{backup_name="test_backup",backup_type="differential"} 100
+medusa_backup_objects{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_size_bytes Backup size.
 # TYPE medusa_backup_size_bytes gauge
-medusa_backup_size_bytes{backup_name="test_backup",backup_type="differential"} 1024
+medusa_backup_size_bytes{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 1024
 # HELP medusa_backup_status Backup status.
 # TYPE medusa_backup_status gauge
-medusa_backup_status{backup_name="test_backup",backup_type="differential"} 0
+medusa_backup_status{backup_name="test_backup",backup_type="differential",cluster="default",prefix="no-prefix"} 0
 # HELP medusa_node_backup_duration_seconds Node backup duration.
 # TYPE medusa_node_backup_duration_seconds gauge
-medusa_node_backup_duration_seconds{backup_name="test_backup",backup_type="differential",node_fqdn="node1.example.com",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
+medusa_node_backup_duration_seconds{backup_name="test_backup",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="no-prefix",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
 # HELP medusa_node_backup_info Node backup info.
 # TYPE medusa_node_backup_info gauge
-medusa_node_backup_info{backup_name="test_backup",backup_type="differential",node_fqdn="node1.example.com",prefix="no-prefix",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
+medusa_node_backup_info{backup_name="test_backup",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="no-prefix",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
 # HELP medusa_node_backup_objects Number of objects in node backup.
 # TYPE medusa_node_backup_objects gauge
-medusa_node_backup_objects{backup_name="test_backup",backup_type="differential",node_fqdn="node1.example.com"} 100
+medusa_node_backup_objects{backup_name="test_backup",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="no-prefix"} 100
 # HELP medusa_node_backup_size_bytes Node backup size.
 # TYPE medusa_node_backup_size_bytes gauge
-medusa_node_backup_size_bytes{backup_name="test_backup",backup_type="differential",node_fqdn="node1.example.com"} 1024
+medusa_node_backup_size_bytes{backup_name="test_backup",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="no-prefix"} 1024
 # HELP medusa_node_backup_status Node backup status.
 # TYPE medusa_node_backup_status gauge
-medusa_node_backup_status{backup_name="test_backup",backup_type="differential",node_fqdn="node1.example.com"} 0
+medusa_node_backup_status{backup_name="test_backup",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="no-prefix"} 0
 `,
 			},
 		},
@@ -124,43 +140,43 @@ medusa_node_backup_status{backup_name="test_backup",backup_type="differential",n
 				setUpMetricValue,
 				`# HELP medusa_backup_completed_nodes Number of completed nodes in backup.
 # TYPE medusa_backup_completed_nodes gauge
-medusa_backup_completed_nodes{backup_name="test_backup_incomplete",backup_type="differential"} 0
+medusa_backup_completed_nodes{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 0
 # HELP medusa_backup_duration_seconds Backup duration.
 # TYPE medusa_backup_duration_seconds gauge
-medusa_backup_duration_seconds{backup_name="test_backup_incomplete",backup_type="differential",start_time="2023-10-19 10:38:20",stop_time="none"} 0
+medusa_backup_duration_seconds{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod",start_time="2023-10-19 10:38:20",stop_time="none"} 0
 # HELP medusa_backup_incomplete_nodes Number of incomplete nodes in backup.
 # TYPE medusa_backup_incomplete_nodes gauge
-medusa_backup_incomplete_nodes{backup_name="test_backup_incomplete",backup_type="differential"} 1
+medusa_backup_incomplete_nodes{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 1
 # HELP medusa_backup_info Backup info.
 # TYPE medusa_backup_info gauge
-medusa_backup_info{backup_name="test_backup_incomplete",backup_type="differential",prefix="prod",start_time="2023-10-19 10:38:20"} 1
+medusa_backup_info{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod",start_time="2023-10-19 10:38:20"} 1
 # HELP medusa_backup_missing_nodes Number of missing nodes in backup.
 # TYPE medusa_backup_missing_nodes gauge
-medusa_backup_missing_nodes{backup_name="test_backup_incomplete",backup_type="differential"} 0
+medusa_backup_missing_nodes{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 0
 # HELP medusa_backup_objects Number of objects in backup.
 # TYPE medusa_backup_objects gauge
-medusa_backup_objects{backup_name="test_backup_incomplete",backup_type="differential"} 0
+medusa_backup_objects{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 0
 # HELP medusa_backup_size_bytes Backup size.
 # TYPE medusa_backup_size_bytes gauge
-medusa_backup_size_bytes{backup_name="test_backup_incomplete",backup_type="differential"} 0
+medusa_backup_size_bytes{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 0
 # HELP medusa_backup_status Backup status.
 # TYPE medusa_backup_status gauge
-medusa_backup_status{backup_name="test_backup_incomplete",backup_type="differential"} 1
+medusa_backup_status{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",prefix="prod"} 1
 # HELP medusa_node_backup_duration_seconds Node backup duration.
 # TYPE medusa_node_backup_duration_seconds gauge
-medusa_node_backup_duration_seconds{backup_name="test_backup_incomplete",backup_type="differential",node_fqdn="node1.example.com",start_time="2023-10-19 10:38:20",stop_time="none"} 0
+medusa_node_backup_duration_seconds{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="prod",start_time="2023-10-19 10:38:20",stop_time="none"} 0
 # HELP medusa_node_backup_info Node backup info.
 # TYPE medusa_node_backup_info gauge
-medusa_node_backup_info{backup_name="test_backup_incomplete",backup_type="differential",node_fqdn="node1.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
+medusa_node_backup_info{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
 # HELP medusa_node_backup_objects Number of objects in node backup.
 # TYPE medusa_node_backup_objects gauge
-medusa_node_backup_objects{backup_name="test_backup_incomplete",backup_type="differential",node_fqdn="node1.example.com"} 0
+medusa_node_backup_objects{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 0
 # HELP medusa_node_backup_size_bytes Node backup size.
 # TYPE medusa_node_backup_size_bytes gauge
-medusa_node_backup_size_bytes{backup_name="test_backup_incomplete",backup_type="differential",node_fqdn="node1.example.com"} 0
+medusa_node_backup_size_bytes{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 0
 # HELP medusa_node_backup_status Node backup status.
 # TYPE medusa_node_backup_status gauge
-medusa_node_backup_status{backup_name="test_backup_incomplete",backup_type="differential",node_fqdn="node1.example.com"} 1
+medusa_node_backup_status{backup_name="test_backup_incomplete",backup_type="differential",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 1
 `,
 			},
 		},
@@ -223,68 +239,68 @@ medusa_node_backup_status{backup_name="test_backup_incomplete",backup_type="diff
 				setUpMetricValue,
 				`# HELP medusa_backup_completed_nodes Number of completed nodes in backup.
 # TYPE medusa_backup_completed_nodes gauge
-medusa_backup_completed_nodes{backup_name="test_backup_combined",backup_type="full"} 2
+medusa_backup_completed_nodes{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 2
 # HELP medusa_backup_duration_seconds Backup duration.
 # TYPE medusa_backup_duration_seconds gauge
-medusa_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",start_time="2023-10-19 10:38:20",stop_time="none"} 0
+medusa_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod",start_time="2023-10-19 10:38:20",stop_time="none"} 0
 # HELP medusa_backup_incomplete_nodes Number of incomplete nodes in backup.
 # TYPE medusa_backup_incomplete_nodes gauge
-medusa_backup_incomplete_nodes{backup_name="test_backup_combined",backup_type="full"} 2
+medusa_backup_incomplete_nodes{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 2
 # HELP medusa_backup_info Backup info.
 # TYPE medusa_backup_info gauge
-medusa_backup_info{backup_name="test_backup_combined",backup_type="full",prefix="prod",start_time="2023-10-19 10:38:20"} 1
+medusa_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod",start_time="2023-10-19 10:38:20"} 1
 # HELP medusa_backup_missing_nodes Number of missing nodes in backup.
 # TYPE medusa_backup_missing_nodes gauge
-medusa_backup_missing_nodes{backup_name="test_backup_combined",backup_type="full"} 2
+medusa_backup_missing_nodes{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 2
 # HELP medusa_backup_objects Number of objects in backup.
 # TYPE medusa_backup_objects gauge
-medusa_backup_objects{backup_name="test_backup_combined",backup_type="full"} 200
+medusa_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 200
 # HELP medusa_backup_size_bytes Backup size.
 # TYPE medusa_backup_size_bytes gauge
-medusa_backup_size_bytes{backup_name="test_backup_combined",backup_type="full"} 2048
+medusa_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 2048
 # HELP medusa_backup_status Backup status.
 # TYPE medusa_backup_status gauge
-medusa_backup_status{backup_name="test_backup_combined",backup_type="full"} 1
+medusa_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",prefix="prod"} 1
 # HELP medusa_node_backup_duration_seconds Node backup duration.
 # TYPE medusa_node_backup_duration_seconds gauge
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node1.example.com",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node2.example.com",start_time="2023-10-19 10:38:40",stop_time="2023-10-19 10:40:50"} 130
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node3.example.com",start_time="2023-10-19 10:39:10",stop_time="none"} 0
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node4.example.com",start_time="2023-10-19 10:39:20",stop_time="none"} 0
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node5.example.com",start_time="none",stop_time="none"} 0
-medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",node_fqdn="node6.example.com",start_time="none",stop_time="none"} 0
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node1.example.com",prefix="prod",start_time="2023-10-19 10:38:20",stop_time="2023-10-19 10:40:00"} 100
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node2.example.com",prefix="prod",start_time="2023-10-19 10:38:40",stop_time="2023-10-19 10:40:50"} 130
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node3.example.com",prefix="prod",start_time="2023-10-19 10:39:10",stop_time="none"} 0
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node4.example.com",prefix="prod",start_time="2023-10-19 10:39:20",stop_time="none"} 0
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node5.example.com",prefix="prod",start_time="none",stop_time="none"} 0
+medusa_node_backup_duration_seconds{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node6.example.com",prefix="prod",start_time="none",stop_time="none"} 0
 # HELP medusa_node_backup_info Node backup info.
 # TYPE medusa_node_backup_info gauge
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node1.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node2.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:40"} 1
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node3.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:39:10"} 1
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node4.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:39:20"} 1
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node5.example.com",prefix="prod",release_version="none",server_type="none",start_time="none"} 1
-medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",node_fqdn="node6.example.com",prefix="prod",release_version="none",server_type="none",start_time="none"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node1.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:20"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node2.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:38:40"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node3.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:39:10"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node4.example.com",prefix="prod",release_version="5.0.4",server_type="cassandra",start_time="2023-10-19 10:39:20"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node5.example.com",prefix="prod",release_version="none",server_type="none",start_time="none"} 1
+medusa_node_backup_info{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node6.example.com",prefix="prod",release_version="none",server_type="none",start_time="none"} 1
 # HELP medusa_node_backup_objects Number of objects in node backup.
 # TYPE medusa_node_backup_objects gauge
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node1.example.com"} 100
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node2.example.com"} 100
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node3.example.com"} 0
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node4.example.com"} 0
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node5.example.com"} 0
-medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",node_fqdn="node6.example.com"} 0
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 100
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node2.example.com",prefix="prod"} 100
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node3.example.com",prefix="prod"} 0
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node4.example.com",prefix="prod"} 0
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node5.example.com",prefix="prod"} 0
+medusa_node_backup_objects{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node6.example.com",prefix="prod"} 0
 # HELP medusa_node_backup_size_bytes Node backup size.
 # TYPE medusa_node_backup_size_bytes gauge
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node1.example.com"} 1024
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node2.example.com"} 1024
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node3.example.com"} 0
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node4.example.com"} 0
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node5.example.com"} 0
-medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",node_fqdn="node6.example.com"} 0
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 1024
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node2.example.com",prefix="prod"} 1024
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node3.example.com",prefix="prod"} 0
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node4.example.com",prefix="prod"} 0
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node5.example.com",prefix="prod"} 0
+medusa_node_backup_size_bytes{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node6.example.com",prefix="prod"} 0
 # HELP medusa_node_backup_status Node backup status.
 # TYPE medusa_node_backup_status gauge
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node1.example.com"} 0
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node2.example.com"} 0
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node3.example.com"} 1
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node4.example.com"} 1
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node5.example.com"} 2
-medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",node_fqdn="node6.example.com"} 2
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node1.example.com",prefix="prod"} 0
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node2.example.com",prefix="prod"} 0
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node3.example.com",prefix="prod"} 1
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node4.example.com",prefix="prod"} 1
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node5.example.com",prefix="prod"} 2
+medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",cluster="default",node_fqdn="node6.example.com",prefix="prod"} 2
 `,
 			},
 		},
@@ -292,7 +308,7 @@ medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			resetBackupMetrics()
-			getBackupMetrics(tt.args.backupData, tt.args.prefix, tt.args.setUpMetricValueFun, logger)
+			getBackupMetrics(tt.args.backupData, "", tt.args.prefix, tt.args.setUpMetricValueFun, logger)
 			reg := prometheus.NewRegistry()
 			reg.MustRegister(
 				medusaBackupInfoMetric,
@@ -329,6 +345,19 @@ medusa_node_backup_status{backup_name="test_backup_combined",backup_type="full",
 	}
 }
 
+func TestGetBackupMetricsNodeFinishedWithoutTime(t *testing.T) {
+	resetBackupMetrics()
+	// Backup from gRPC source, finish time of nodes is unknown.
+	backupData := testGRPCIndexBackups()[0]
+	getBackupMetrics(backupData, "", "", setUpMetricValue, logger)
+	for _, fqdn := range []string{"node1", "node2"} {
+		got := getGaugeValue(t, medusaNodeBackupsStatusMetric.WithLabelValues("full_1", fullLabel, defaultClusterLabel, fqdn, noPrefixLabel))
+		if got != statusComplete {
+			t.Errorf("\nVariables do not match for %s:\ngot: %v\nwant: %v", fqdn, got, statusComplete)
+		}
+	}
+}
+
 func TestGetBackupMetricsErrorsAndDebugs(t *testing.T) {
 	type args struct {
 		backupData          backup
@@ -423,7 +452,7 @@ func TestGetBackupMetricsErrorsAndDebugs(t *testing.T) {
 			resetBackupMetrics()
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			getBackupMetrics(tt.args.backupData, tt.args.prefix, tt.args.setUpMetricValueFun, lc)
+			getBackupMetrics(tt.args.backupData, "", tt.args.prefix, tt.args.setUpMetricValueFun, lc)
 			errorsOutputCount := strings.Count(out.String(), "level=ERROR")
 			debugsOutputCount := strings.Count(out.String(), "level=DEBUG")
 			if tt.args.errorsCount != errorsOutputCount || tt.args.debugsCount != debugsOutputCount {
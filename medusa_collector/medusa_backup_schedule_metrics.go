@@ -0,0 +1,168 @@
+package medusa_collector
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Default max delay of backup start after expected start.
+	defaultBackupScheduleGrace = time.Hour
+	// Default time window for comparing backups with expected schedule.
+	defaultBackupScheduleLookback = 7 * 24 * time.Hour
+)
+
+var (
+	medusaBackupScheduleExpectedMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_schedule_expected_backups",
+		Help: "Number of expected backup starts by schedule within lookback window.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupScheduleMissedMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_schedule_missed_backups",
+		Help: "Number of expected backup starts without backup within lookback window.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupScheduleLateMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_schedule_late_backups",
+		Help: "Number of backups started later than expected start plus grace within lookback window.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupScheduleExtraMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_schedule_extra_backups",
+		Help: "Number of unexpected extra backups within lookback window.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+)
+
+// Result of comparing backups with expected schedule.
+type backupScheduleStats struct {
+	expected int
+	missed   int
+	late     int
+	extra    int
+}
+
+// Set backup metrics:
+//   - medusa_backup_schedule_expected_backups
+//   - medusa_backup_schedule_missed_backups
+//   - medusa_backup_schedule_late_backups
+//   - medusa_backup_schedule_extra_backups
+func getBackupScheduleMetrics(backups []backup, config BackupScheduleConfig, currentUnixTime int64, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	if len(config.Cron) == 0 {
+		return
+	}
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
+	location := time.Local
+	if config.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(config.TimeZone); err != nil {
+			// Configuration is validated on load, so it isn't expected.
+			logger.Error("Invalid time zone of backup schedule", "timezone", config.TimeZone, "err", err)
+			return
+		}
+	}
+	grace := time.Duration(config.Grace)
+	if grace == 0 {
+		grace = defaultBackupScheduleGrace
+	}
+	lookback := time.Duration(config.Lookback)
+	if lookback == 0 {
+		lookback = defaultBackupScheduleLookback
+	}
+	now := time.Unix(currentUnixTime, 0).In(location)
+	for _, backupType := range []string{differentialLabel, fullLabel} {
+		spec, ok := config.Cron[backupType]
+		if !ok {
+			continue
+		}
+		cron, err := parseCron(spec)
+		if err != nil {
+			logger.Error("Invalid cron expression of backup schedule", "backup_type", backupType, "cron", spec, "err", err)
+			continue
+		}
+		var started []int64
+		for _, singleBackup := range backups {
+			if singleBackup.BackupType == backupType && singleBackup.Started > 0 {
+				started = append(started, singleBackup.Started)
+			}
+		}
+		stats := compareBackupSchedule(cron, started, now.Add(-lookback), now, grace)
+		for _, metric := range []struct {
+			metric *prometheus.GaugeVec
+			name   string
+			value  int
+		}{
+			{medusaBackupScheduleExpectedMetric, "medusa_backup_schedule_expected_backups", stats.expected},
+			{medusaBackupScheduleMissedMetric, "medusa_backup_schedule_missed_backups", stats.missed},
+			{medusaBackupScheduleLateMetric, "medusa_backup_schedule_late_backups", stats.late},
+			{medusaBackupScheduleExtraMetric, "medusa_backup_schedule_extra_backups", stats.extra},
+		} {
+			setUpMetric(
+				metric.metric,
+				metric.name,
+				float64(metric.value),
+				setUpMetricValueFun,
+				logger,
+				backupType,
+				cluster,
+				prefix,
+			)
+		}
+	}
+}
+
+// Compare start times of backups with expected starts by cron within [windowStart, now].
+// Each backup belongs to the last expected start before it.
+// The first backup of expected start is late if it's started after grace,
+// other backups of the same expected start are extra.
+// Expected start without backup is missed after grace, before that it isn't counted.
+// Backups, which belong to expected start before window, aren't counted.
+func compareBackupSchedule(cron *cronSchedule, started []int64, windowStart, now time.Time, grace time.Duration) backupScheduleStats {
+	var expectedStarts []time.Time
+	for t := cron.next(windowStart.Add(-time.Nanosecond)); !t.IsZero() && !t.After(now); t = cron.next(t) {
+		expectedStarts = append(expectedStarts, t)
+	}
+	started = slices.Sorted(slices.Values(started))
+	var stats backupScheduleStats
+	j := 0
+	for i, expectedStart := range expectedStarts {
+		var matched []int64
+		for ; j < len(started); j++ {
+			if i+1 < len(expectedStarts) && started[j] >= expectedStarts[i+1].Unix() {
+				break
+			}
+			if started[j] >= expectedStart.Unix() {
+				matched = append(matched, started[j])
+			}
+		}
+		switch {
+		case len(matched) == 0 && now.Sub(expectedStart) <= grace:
+			// Backup can still be started on time.
+			continue
+		case len(matched) == 0:
+			stats.missed++
+		case time.Unix(matched[0], 0).Sub(expectedStart) > grace:
+			stats.late++
+		}
+		stats.expected++
+		stats.extra += max(len(matched)-1, 0)
+	}
+	return stats
+}
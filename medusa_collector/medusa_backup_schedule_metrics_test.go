@@ -0,0 +1,213 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func resetBackupScheduleMetrics() {
+	medusaBackupScheduleExpectedMetric.Reset()
+	medusaBackupScheduleMissedMetric.Reset()
+	medusaBackupScheduleLateMetric.Reset()
+	medusaBackupScheduleExtraMetric.Reset()
+}
+
+// Return unix time for day of October 2023 in UTC.
+func octoberTime(day, hour, minute int) int64 {
+	return time.Date(2023, 10, day, hour, minute, 0, 0, time.UTC).Unix()
+}
+
+// Return start times of daily backups for days of October 2023.
+func dailyStarts(fromDay, toDay, hour, minute int) []int64 {
+	var started []int64
+	for day := fromDay; day <= toDay; day++ {
+		started = append(started, octoberTime(day, hour, minute))
+	}
+	return started
+}
+
+func TestCompareBackupSchedule(t *testing.T) {
+	cron, err := parseCron("0 1 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(octoberTime(19, 12, 0), 0).UTC()
+	tests := []struct {
+		name    string
+		started []int64
+		now     time.Time
+		want    backupScheduleStats
+	}{
+		{"OnTime", dailyStarts(13, 19, 1, 5), now, backupScheduleStats{expected: 7}},
+		{"NoBackups", nil, now, backupScheduleStats{expected: 7, missed: 7}},
+		{
+			"Missed",
+			append(dailyStarts(13, 14, 1, 5), dailyStarts(17, 19, 1, 5)...),
+			now,
+			backupScheduleStats{expected: 7, missed: 2},
+		},
+		{
+			"Late",
+			append(dailyStarts(13, 16, 1, 5), octoberTime(17, 3, 0), octoberTime(18, 1, 5), octoberTime(19, 1, 5)),
+			now,
+			backupScheduleStats{expected: 7, late: 1},
+		},
+		{
+			"Extra",
+			append(dailyStarts(13, 19, 1, 5), octoberTime(18, 13, 0), octoberTime(19, 11, 0)),
+			now,
+			backupScheduleStats{expected: 7, extra: 2},
+		},
+		{
+			"BackupBeforeWindow",
+			append(dailyStarts(13, 19, 1, 5), octoberTime(12, 13, 0)),
+			now,
+			backupScheduleStats{expected: 7},
+		},
+		{
+			"PendingExpectedStart",
+			dailyStarts(13, 18, 1, 5),
+			time.Unix(octoberTime(19, 1, 30), 0).UTC(),
+			backupScheduleStats{expected: 6},
+		},
+		{
+			"StartedOnTimeWithinGrace",
+			dailyStarts(13, 19, 1, 5),
+			time.Unix(octoberTime(19, 1, 30), 0).UTC(),
+			backupScheduleStats{expected: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareBackupSchedule(cron, tt.started, tt.now.Add(-7*24*time.Hour), tt.now, time.Hour)
+			if got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBackupScheduleMetrics(t *testing.T) {
+	var backups []backup
+	for _, started := range dailyStarts(13, 19, 1, 5) {
+		backups = append(backups, backup{Name: "full", BackupType: fullLabel, Started: started})
+	}
+	tests := []struct {
+		name     string
+		config   BackupScheduleConfig
+		testText string
+	}{
+		{"NoSchedule", BackupScheduleConfig{}, ""},
+		{
+			"DefaultGraceAndLookback",
+			BackupScheduleConfig{Cron: map[string]string{fullLabel: "0 1 * * *", differentialLabel: "0 13 * * *"}, TimeZone: "UTC"},
+			`# HELP medusa_backup_schedule_expected_backups Number of expected backup starts by schedule within lookback window.
+# TYPE medusa_backup_schedule_expected_backups gauge
+medusa_backup_schedule_expected_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 7
+medusa_backup_schedule_expected_backups{backup_type="full",cluster="default",prefix="no-prefix"} 7
+# HELP medusa_backup_schedule_extra_backups Number of unexpected extra backups within lookback window.
+# TYPE medusa_backup_schedule_extra_backups gauge
+medusa_backup_schedule_extra_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+medusa_backup_schedule_extra_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_schedule_late_backups Number of backups started later than expected start plus grace within lookback window.
+# TYPE medusa_backup_schedule_late_backups gauge
+medusa_backup_schedule_late_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+medusa_backup_schedule_late_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_schedule_missed_backups Number of expected backup starts without backup within lookback window.
+# TYPE medusa_backup_schedule_missed_backups gauge
+medusa_backup_schedule_missed_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 7
+medusa_backup_schedule_missed_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+`,
+		},
+		{
+			"TimeZoneGraceAndLookback",
+			BackupScheduleConfig{
+				// 01:00 UTC is 03:00 in Berlin.
+				Cron:     map[string]string{fullLabel: "0 3 * * *"},
+				Grace:    model.Duration(time.Minute),
+				Lookback: model.Duration(2 * 24 * time.Hour),
+				TimeZone: "Europe/Berlin",
+			},
+			`# HELP medusa_backup_schedule_expected_backups Number of expected backup starts by schedule within lookback window.
+# TYPE medusa_backup_schedule_expected_backups gauge
+medusa_backup_schedule_expected_backups{backup_type="full",cluster="default",prefix="no-prefix"} 2
+# HELP medusa_backup_schedule_extra_backups Number of unexpected extra backups within lookback window.
+# TYPE medusa_backup_schedule_extra_backups gauge
+medusa_backup_schedule_extra_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_schedule_late_backups Number of backups started later than expected start plus grace within lookback window.
+# TYPE medusa_backup_schedule_late_backups gauge
+medusa_backup_schedule_late_backups{backup_type="full",cluster="default",prefix="no-prefix"} 2
+# HELP medusa_backup_schedule_missed_backups Number of expected backup starts without backup within lookback window.
+# TYPE medusa_backup_schedule_missed_backups gauge
+medusa_backup_schedule_missed_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := time.LoadLocation(tt.config.TimeZone); err != nil {
+				t.Skip(err)
+			}
+			resetBackupScheduleMetrics()
+			getBackupScheduleMetrics(backups, tt.config, octoberTime(19, 12, 0), "", "", setUpMetricValue, logger)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(
+				medusaBackupScheduleExpectedMetric,
+				medusaBackupScheduleMissedMetric,
+				medusaBackupScheduleLateMetric,
+				medusaBackupScheduleExtraMetric,
+			)
+			if got := gatherText(t, reg); got != tt.testText {
+				t.Errorf("\nVariables do not match, metrics:\n%s\nwant:\n%s", got, tt.testText)
+			}
+		})
+	}
+}
+
+func TestGetMedusaInfoBackupSchedule(t *testing.T) {
+	resetStagingMetrics()
+	target := Target{
+		Cluster: "schedule",
+		Thresholds: ThresholdConfig{BackupSchedule: BackupScheduleConfig{
+			Cron: map[string]string{fullLabel: "0 * * * *"},
+		}},
+		Source: &fakeSource{backups: testIndexBackups()},
+	}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	text := gatherText(t, snapshotGatherer())
+	// Backups from test index are older than lookback window.
+	want := `medusa_backup_schedule_extra_backups{backup_type="full",cluster="schedule",prefix="no-prefix"} 0`
+	if !strings.Contains(text, want) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", text, want)
+	}
+	if strings.Contains(text, `medusa_backup_schedule_missed_backups{backup_type="differential",cluster="schedule"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no metrics for backup type without schedule", text)
+	}
+}
+
+func TestGetMedusaInfoBackupScheduleFailedCollection(t *testing.T) {
+	resetStagingMetrics()
+	target := Target{
+		Cluster: "schedule_failed",
+		Thresholds: ThresholdConfig{BackupSchedule: BackupScheduleConfig{
+			Cron: map[string]string{fullLabel: "0 1 * * *"},
+		}},
+		Source: &fakeSource{errs: []error{errors.New("failed")}},
+	}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	text := gatherText(t, snapshotGatherer())
+	// Exporter can't get data, it doesn't mean that backups are missed.
+	if strings.Contains(text, `medusa_backup_schedule_missed_backups{backup_type="full",cluster="schedule_failed"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no schedule metrics for failed collection", text)
+	}
+	want := `medusa_exporter_status{cluster="schedule_failed",prefix="no-prefix",reason="error"} 0`
+	if !strings.Contains(text, want) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", text, want)
+	}
+}
@@ -0,0 +1,286 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const collectPath = "/-/collect"
+
+var collectionLoop *CollectionLoop
+
+// SetCollectionLoop sets loop, which runs collection on request
+// to '/-/collect' endpoint.
+func SetCollectionLoop(loop *CollectionLoop) {
+	collectionLoop = loop
+}
+
+// CollectionLoop gets data from Medusa by collection schedule and on demand.
+// On-demand requests join running collection, if it gets data for requested targets,
+// other requests are coalesced into one next collection.
+type CollectionLoop struct {
+	manager *ConfigManager
+	logger  *slog.Logger
+	// Wakes the loop for on-demand collection.
+	wake chan struct{}
+	mu   sync.Mutex
+	// Collection, which is in progress.
+	running *collectRound
+	// On-demand collection, which isn't started yet.
+	pending *collectRound
+}
+
+// One collection shared by coalesced requests.
+type collectRound struct {
+	// Names of requested targets, aren't changed after collection is started.
+	targets map[string]bool
+	// Closed when collection is done.
+	done    chan struct{}
+	summary []targetSummary
+}
+
+// Summary of getting data from Medusa for one target.
+type targetSummary struct {
+	Target  string `json:"target"`
+	Cluster string `json:"cluster"`
+	Prefix  string `json:"prefix"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	// Number of backups, which match backup filters.
+	Backups int `json:"backups"`
+	// Number of finished backups, which match backup filters.
+	FinishedBackups int `json:"finished_backups"`
+	// The last started backup, which matches backup filters.
+	LastBackup *backupSummary `json:"last_backup,omitempty"`
+}
+
+// Summary of one backup.
+type backupSummary struct {
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// NewCollectionLoop creates loop for targets and collecting parameters of manager.
+func NewCollectionLoop(manager *ConfigManager, logger *slog.Logger) *CollectionLoop {
+	return &CollectionLoop{
+		manager: manager,
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Run gets data from Medusa for all targets by collection schedule
+// and for requested targets on demand, until ctx is canceled.
+// The first collection is started immediately.
+// On-demand collections don't shift scheduled collections.
+func (l *CollectionLoop) Run(ctx context.Context) {
+	var planned time.Time
+	next := time.Now()
+	for {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			l.collect(ctx, true)
+			planned, next = l.schedule(planned)
+		case <-l.wake:
+			timer.Stop()
+			l.collect(ctx, false)
+		}
+	}
+}
+
+// Return planned time of the next collection and time with jitter,
+// when collection is started. Schedule can be changed on reload.
+func (l *CollectionLoop) schedule(planned time.Time) (time.Time, time.Time) {
+	schedule, errs := newCollectionSchedule(l.manager.Collection())
+	for _, err := range errs {
+		// Configuration is validated on load, so it isn't expected.
+		l.logger.Error("Invalid collection schedule", "err", err)
+	}
+	planned = schedule.next(planned, time.Now())
+	next := planned.Add(schedule.delay())
+	getExporterScheduleMetrics(next)
+	l.logger.Debug("Next collection is scheduled", "time", next)
+	return planned, next
+}
+
+// Get data from Medusa and complete pending on-demand collection.
+// Scheduled collection gets data for all targets,
+// on-demand collection only for requested targets.
+func (l *CollectionLoop) collect(ctx context.Context, scheduled bool) {
+	targets := l.manager.Targets()
+	l.mu.Lock()
+	round := l.pending
+	l.pending = nil
+	if !scheduled && round == nil {
+		// Pending collection is already completed by scheduled collection.
+		l.mu.Unlock()
+		return
+	}
+	if round == nil {
+		round = newCollectRound()
+	}
+	if scheduled {
+		// Pending collection is completed by scheduled collection.
+		for _, target := range targets {
+			round.targets[target.Name()] = true
+		}
+	} else {
+		requested := make([]Target, 0, len(round.targets))
+		for _, target := range targets {
+			if round.targets[target.Name()] {
+				requested = append(requested, target)
+			}
+		}
+		targets = requested
+	}
+	l.running = round
+	l.mu.Unlock()
+	if !scheduled {
+		l.logger.Info("Getting data from Medusa on demand", "targets", len(targets))
+	}
+	round.summary = getMedusaInfo(ctx, targets, l.logger)
+	l.mu.Lock()
+	l.running = nil
+	l.mu.Unlock()
+	close(round.done)
+}
+
+func newCollectRound() *collectRound {
+	return &collectRound{
+		targets: make(map[string]bool),
+		done:    make(chan struct{}),
+	}
+}
+
+// Return true if collection gets data for all targets with names.
+func (r *collectRound) covers(names []string) bool {
+	for _, name := range names {
+		if !r.targets[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Collect requests collection for targets with names and waits for its summary.
+// Request joins running collection, if it gets data for all targets with names.
+// Otherwise, requests share one collection, which is started after running one.
+func (l *CollectionLoop) Collect(ctx context.Context, names []string) ([]targetSummary, error) {
+	l.mu.Lock()
+	round := l.running
+	if round == nil || !round.covers(names) {
+		round = l.pending
+		if round == nil {
+			round = newCollectRound()
+			l.pending = round
+		}
+		for _, name := range names {
+			round.targets[name] = true
+		}
+		select {
+		case l.wake <- struct{}{}:
+		default:
+			// Loop is already woken.
+		}
+	}
+	l.mu.Unlock()
+	select {
+	case <-round.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		requested[name] = true
+	}
+	summary := make([]targetSummary, 0, len(names))
+	for _, targetSummary := range round.summary {
+		if requested[targetSummary.Target] {
+			summary = append(summary, targetSummary)
+		}
+	}
+	return summary, nil
+}
+
+// Return summary of backups, which match backup filters, for target.
+func summarizeTarget(target Target, result targetResult, filters FilterConfig) targetSummary {
+	labels := targetLabels(target.Cluster, target.Prefix)
+	summary := targetSummary{
+		Target:  target.Name(),
+		Cluster: labels["cluster"],
+		Prefix:  labels["prefix"],
+		Success: result.err == nil,
+	}
+	if result.err != nil {
+		summary.Error = result.err.Error()
+	}
+	var lastBackup backup
+	for _, singleBackup := range result.backups {
+		if !filters.match(singleBackup) {
+			continue
+		}
+		summary.Backups++
+		if singleBackup.Finished > 0 {
+			summary.FinishedBackups++
+		}
+		if singleBackup.Started >= lastBackup.Started {
+			lastBackup = singleBackup
+		}
+	}
+	if summary.Backups > 0 {
+		summary.LastBackup = &backupSummary{
+			Name:    lastBackup.Name,
+			Type:    lastBackup.BackupType,
+			Started: time.Unix(lastBackup.Started, 0).UTC(),
+		}
+		if lastBackup.Finished > 0 {
+			finished := time.Unix(lastBackup.Finished, 0).UTC()
+			summary.LastBackup.Finished = &finished
+		}
+	}
+	return summary
+}
+
+// Handler runs collection for all current targets of manager or for targets
+// with cluster and prefix from query parameters and returns JSON summary.
+func collectHandler(loop *CollectionLoop, manager *ConfigManager, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Only POST requests allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		cluster, prefix := r.URL.Query().Get("cluster"), r.URL.Query().Get("prefix")
+		var names []string
+		for _, target := range manager.Targets() {
+			labels := targetLabels(target.Cluster, target.Prefix)
+			if (cluster == "" || cluster == labels["cluster"]) && (prefix == "" || prefix == labels["prefix"]) {
+				names = append(names, target.Name())
+			}
+		}
+		if len(names) == 0 {
+			http.Error(w, fmt.Sprintf("No targets for cluster %q and prefix %q", cluster, prefix), http.StatusNotFound)
+			return
+		}
+		summary, err := loop.Collect(r.Context(), names)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Collection isn't completed: %s", err), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			logger.Error("Write collection summary failed", "err", err)
+		}
+	})
+}
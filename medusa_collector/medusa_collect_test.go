@@ -0,0 +1,258 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Source which blocks until it's released.
+type blockingSource struct {
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func newBlockingSource() *blockingSource {
+	return &blockingSource{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (s *blockingSource) List(_ context.Context, _ setUpMetricValueFunType) ([]backup, error) {
+	s.calls.Add(1)
+	s.started <- struct{}{}
+	<-s.release
+	return testIndexBackups(), nil
+}
+
+func (s *blockingSource) Name() string {
+	return "blocking"
+}
+
+func (s *blockingSource) Close() error {
+	return nil
+}
+
+// Wait for call of source and release it.
+func (s *blockingSource) next() {
+	<-s.started
+	s.release <- struct{}{}
+}
+
+// Wait until running collection of loop is completed.
+func waitCollectionDone(loop *CollectionLoop) {
+	for {
+		loop.mu.Lock()
+		running := loop.running
+		loop.mu.Unlock()
+		if running == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCollectionLoopCoalesce(t *testing.T) {
+	source := newBlockingSource()
+	target := Target{Cluster: "loop", Source: source}
+	other := &fakeSource{backups: testIndexBackups()}
+	otherTarget := Target{Cluster: "loop", Prefix: "other", Source: other}
+	manager := newTestConfigManager([]Target{target, otherTarget}, CollectionConfig{Interval: model.Duration(time.Hour)})
+	loop := NewCollectionLoop(manager, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loop.Run(ctx)
+	// The first collection is started immediately.
+	source.next()
+	waitCollectionDone(loop)
+	// Collection on demand is in progress.
+	first := make(chan error, 1)
+	go func() {
+		_, err := loop.Collect(ctx, []string{target.Name()})
+		first <- err
+	}()
+	<-source.started
+	// Requests for the same target made during collection join it.
+	var joined sync.WaitGroup
+	for range 5 {
+		joined.Add(1)
+		go func() {
+			defer joined.Done()
+			summary, err := loop.Collect(ctx, []string{target.Name()})
+			if err != nil || len(summary) != 1 || !summary[0].Success {
+				t.Errorf("\nVariables do not match:\ngot: %+v, %v\nwant: successful summary for one target", summary, err)
+			}
+		}()
+	}
+	// Requests for other targets share the next collection.
+	var queued sync.WaitGroup
+	for range 3 {
+		queued.Add(1)
+		go func() {
+			defer queued.Done()
+			summary, err := loop.Collect(ctx, []string{target.Name(), otherTarget.Name()})
+			if err != nil || len(summary) != 2 {
+				t.Errorf("\nVariables do not match:\ngot: %+v, %v\nwant: summary for two targets", summary, err)
+			}
+		}()
+	}
+	// Wait for requests.
+	time.Sleep(100 * time.Millisecond)
+	source.release <- struct{}{}
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	// Joined requests don't wait for the next collection.
+	joined.Wait()
+	source.next()
+	queued.Wait()
+	if got := source.calls.Load(); got != 3 {
+		t.Errorf("\nVariables do not match:\ngot: %d calls\nwant: %d calls", got, 3)
+	}
+}
+
+func TestCollectionLoopCollectTargets(t *testing.T) {
+	prod := &fakeSource{backups: testIndexBackups()}
+	dev := &fakeSource{errs: []error{errors.New("failed"), errors.New("failed")}}
+	targets := []Target{{Prefix: "prod", Source: prod}, {Prefix: "dev", Source: dev}}
+	manager := newTestConfigManager(targets, CollectionConfig{Interval: model.Duration(time.Hour)})
+	loop := NewCollectionLoop(manager, logger)
+	// Loop isn't run, so request isn't completed.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := loop.Collect(ctx, []string{"default/prod"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", err, context.DeadlineExceeded)
+	}
+	loop.collect(context.Background(), false)
+	// Only requested target is collected.
+	if prod.calls != 1 || dev.calls != 0 {
+		t.Errorf("\nVariables do not match:\ngot: prod=%d, dev=%d calls\nwant: prod=1, dev=0 calls", prod.calls, dev.calls)
+	}
+	// Scheduled collection gets data for all targets.
+	loop.collect(context.Background(), true)
+	if prod.calls != 2 || dev.calls != 1 {
+		t.Errorf("\nVariables do not match:\ngot: prod=%d, dev=%d calls\nwant: prod=2, dev=1 calls", prod.calls, dev.calls)
+	}
+}
+
+func TestSummarizeTarget(t *testing.T) {
+	target := Target{Cluster: "prod", Prefix: "cluster1"}
+	backups := []backup{
+		{Name: "full_1", BackupType: fullLabel, Started: 1697711000, Finished: 1697712000},
+		{Name: "diff_1", BackupType: differentialLabel, Started: 1697713000, Finished: 1697714000},
+		{Name: "full_2", BackupType: fullLabel, Started: 1697715000},
+	}
+	finished := time.Unix(1697712000, 0).UTC()
+	tests := []struct {
+		name    string
+		result  targetResult
+		filters FilterConfig
+		want    targetSummary
+	}{
+		{
+			"AllBackups",
+			targetResult{backups: backups},
+			FilterConfig{},
+			targetSummary{
+				Target: "prod/cluster1", Cluster: "prod", Prefix: "cluster1", Success: true,
+				Backups: 3, FinishedBackups: 2,
+				LastBackup: &backupSummary{Name: "full_2", Type: fullLabel, Started: time.Unix(1697715000, 0).UTC()},
+			},
+		},
+		{
+			"FilteredBackups",
+			targetResult{backups: backups},
+			FilterConfig{BackupNameInclude: "full_1"},
+			targetSummary{
+				Target: "prod/cluster1", Cluster: "prod", Prefix: "cluster1", Success: true,
+				Backups: 1, FinishedBackups: 1,
+				LastBackup: &backupSummary{Name: "full_1", Type: fullLabel, Started: time.Unix(1697711000, 0).UTC(), Finished: &finished},
+			},
+		},
+		{
+			"Error",
+			targetResult{err: errors.New("exit status 1")},
+			FilterConfig{},
+			targetSummary{Target: "prod/cluster1", Cluster: "prod", Prefix: "cluster1", Error: "exit status 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.filters.compile(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got := summarizeTarget(target, tt.result, tt.filters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectHandler(t *testing.T) {
+	targets := []Target{
+		{Prefix: "prod", Source: &fakeSource{backups: testIndexBackups()}},
+		{Cluster: "dev", Prefix: "prod", Source: &fakeSource{backups: testIndexBackups()}},
+		{Prefix: "test", Source: &fakeSource{backups: testIndexBackups()}},
+	}
+	manager := newTestConfigManager(targets, CollectionConfig{Interval: model.Duration(time.Hour)})
+	loop := NewCollectionLoop(manager, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go loop.Run(ctx)
+	server := httptest.NewServer(collectHandler(loop, manager, logger))
+	defer server.Close()
+	tests := []struct {
+		name        string
+		method      string
+		query       string
+		wantCode    int
+		wantTargets []string
+	}{
+		{"Prefix", http.MethodPost, "?prefix=prod", http.StatusOK, []string{"default/prod", "dev/prod"}},
+		{"ClusterAndPrefix", http.MethodPost, "?cluster=dev&prefix=prod", http.StatusOK, []string{"dev/prod"}},
+		{"AllTargets", http.MethodPost, "", http.StatusOK, []string{"default/prod", "dev/prod", "default/test"}},
+		{"UnknownPrefix", http.MethodPost, "?prefix=unknown", http.StatusNotFound, nil},
+		{"WrongMethod", http.MethodGet, "", http.StatusMethodNotAllowed, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("\nVariables do not match:\ngot: %d\nwant: %d", resp.StatusCode, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var summary []targetSummary
+			if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+				t.Fatal(err)
+			}
+			var gotTargets []string
+			for _, targetSummary := range summary {
+				gotTargets = append(gotTargets, targetSummary.Target)
+			}
+			if !reflect.DeepEqual(gotTargets, tt.wantTargets) {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", gotTargets, tt.wantTargets)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package medusa_collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Default Medusa configuration file.
+// It's used by Medusa when '--config-file' is not specified.
+const defaultMedusaConfigFile = "/etc/medusa/medusa.ini"
+
+// Medusa [storage] section parameters
+// which are required to read backup index directly from storage.
+type storageConfig struct {
+	provider        string
+	bucketName      string
+	basePath        string
+	prefix          string
+	keyFile         string
+	apiProfile      string
+	host            string
+	port            string
+	secure          bool
+	region          string
+	addressingStyle string
+}
+
+// Parse Medusa configuration file.
+// Medusa uses python configparser, so only the basic syntax is supported:
+// sections, 'key = value' or 'key: value' pairs and full line comments.
+// Section and key names are case-insensitive.
+func parseMedusaConfig(data string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			if _, ok := result[section]; !ok {
+				result[section] = make(map[string]string)
+			}
+			continue
+		}
+		if section == "" {
+			return nil, fmt.Errorf("line %d: key outside of section", lineNum)
+		}
+		idx := strings.IndexAny(line, "=:")
+		if idx < 0 {
+			return nil, fmt.Errorf("line %d: invalid key-value pair", lineNum)
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		result[section][key] = strings.TrimSpace(line[idx+1:])
+	}
+	return result, scanner.Err()
+}
+
+// Read [storage] section from Medusa configuration file.
+func readStorageConfig(config string) (storageConfig, error) {
+	if config == "" {
+		config = defaultMedusaConfigFile
+	}
+	data, err := os.ReadFile(config)
+	if err != nil {
+		return storageConfig{}, err
+	}
+	sections, err := parseMedusaConfig(string(data))
+	if err != nil {
+		return storageConfig{}, fmt.Errorf("parse %s: %w", config, err)
+	}
+	storage, ok := sections["storage"]
+	if !ok {
+		return storageConfig{}, fmt.Errorf("parse %s: no [storage] section", config)
+	}
+	return storageConfig{
+		provider:        strings.ToLower(storage["storage_provider"]),
+		bucketName:      storage["bucket_name"],
+		basePath:        storage["base_path"],
+		prefix:          storage["prefix"],
+		keyFile:         storage["key_file"],
+		apiProfile:      storage["api_profile"],
+		host:            storage["host"],
+		port:            storage["port"],
+		secure:          parseConfigBool(storage["secure"], true),
+		region:          storage["region"],
+		addressingStyle: strings.ToLower(storage["s3_addressing_style"]),
+	}, nil
+}
+
+// Parse boolean value the same way as python configparser does.
+// If value is empty or unknown, default value is returned.
+func parseConfigBool(value string, defaultValue bool) bool {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true", "on":
+		return true
+	case "0", "no", "false", "off":
+		return false
+	}
+	return defaultValue
+}
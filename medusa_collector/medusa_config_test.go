@@ -0,0 +1,142 @@
+package medusa_collector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMedusaConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{
+			name: "ValidConfig",
+			data: `[cassandra]
+config_file = /etc/cassandra/cassandra.yaml
+# Comment
+; Another comment
+
+[Storage]
+Storage_Provider = local
+base_path: /var/lib/cassandra/backups
+bucket_name = backup
+`,
+			want: map[string]map[string]string{
+				"cassandra": {"config_file": "/etc/cassandra/cassandra.yaml"},
+				"storage": {
+					"storage_provider": "local",
+					"base_path":        "/var/lib/cassandra/backups",
+					"bucket_name":      "backup",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "KeyOutsideSection",
+			data:    "storage_provider = local\n",
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "InvalidLine",
+			data:    "[storage]\nstorage_provider\n",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMedusaConfig(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStorageConfig(t *testing.T) {
+	dir := t.TempDir()
+	validConfig := filepath.Join(dir, "medusa.ini")
+	if err := os.WriteFile(validConfig, []byte("[storage]\nstorage_provider = local\nbase_path = /tmp\nbucket_name = backup\nprefix = prod\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	noStorageConfig := filepath.Join(dir, "no_storage.ini")
+	if err := os.WriteFile(noStorageConfig, []byte("[cassandra]\nconfig_file = /etc/cassandra/cassandra.yaml\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		config  string
+		want    storageConfig
+		wantErr bool
+	}{
+		{
+			name:   "ValidConfig",
+			config: validConfig,
+			want: storageConfig{
+				provider:   "local",
+				bucketName: "backup",
+				basePath:   "/tmp",
+				prefix:     "prod",
+				secure:     true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "NoStorageSection",
+			config:  noStorageConfig,
+			want:    storageConfig{},
+			wantErr: true,
+		},
+		{
+			name:    "FileNotExist",
+			config:  filepath.Join(dir, "not_exist.ini"),
+			want:    storageConfig{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readStorageConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfigBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        string
+		defaultValue bool
+		want         bool
+	}{
+		{"True", "True", false, true},
+		{"Yes", "yes", false, true},
+		{"False", "False", true, false},
+		{"Off", "off", true, false},
+		{"EmptyDefaultTrue", "", true, true},
+		{"UnknownDefaultFalse", "unknown", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseConfigBool(tt.value, tt.defaultValue); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package medusa_collector
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
-	"os"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/exporter-toolkit/web"
 )
@@ -13,8 +18,48 @@ import (
 var (
 	webFlagsConfig web.FlagConfig
 	webEndpoint    string
+	collectConfig  = CollectConfig{Concurrency: 1}
+	filterConfig   FilterConfig
+	// Guards collecting, retry and filter parameters,
+	// which can be replaced on reload during collection.
+	settingsMutex sync.RWMutex
 )
 
+// CollectConfig contains parameters for collecting metrics.
+type CollectConfig struct {
+	// Keep metrics from the last successful collection on failure.
+	KeepLastGood bool
+	// Max number of targets which are collected concurrently.
+	Concurrency int
+	// Max time of getting data for one target, 0 disables timeout.
+	Timeout time.Duration
+}
+
+// Target is a source of backup data for one cluster and prefix.
+type Target struct {
+	// Name of cluster, used as cluster label.
+	Cluster string
+	Prefix  string
+	// Additional labels for all metrics of the target.
+	Labels map[string]string
+	// Thresholds for backup compliance metrics.
+	Thresholds ThresholdConfig
+	Source     BackupSource
+}
+
+// Name returns target name in 'cluster/prefix' format,
+// where cluster and prefix are values of cluster and prefix labels.
+func (t Target) Name() string {
+	labels := targetLabels(t.Cluster, t.Prefix)
+	return labels["cluster"] + "/" + labels["prefix"]
+}
+
+// Backup data from one target.
+type targetResult struct {
+	backups []backup
+	err     error
+}
+
 // SetPromPortAndPath sets HTTP endpoint parameters
 // from command line arguments:
 // 'web.telemetry-path',
@@ -26,70 +71,222 @@ func SetPromPortAndPath(flagsConfig web.FlagConfig, endpoint string) {
 	webEndpoint = endpoint
 }
 
-// StartPromEndpoint run HTTP endpoint
-func StartPromEndpoint(version string, logger *slog.Logger) {
-	go func(logger *slog.Logger) {
-		if webEndpoint == "" {
-			logger.Error("Metric endpoint is empty", "endpoint", webEndpoint)
-		}
-		http.Handle(webEndpoint, promhttp.Handler())
-		if webEndpoint != "/" {
-			landingConfig := web.LandingConfig{
-				Name:        "Medusa exporter",
-				Description: "Prometheus exporter for Medusa for Apache Cassandra",
-				HeaderColor: "#476b6b",
-				Version:     version,
-				Profiling:   "false",
-				Links: []web.LandingLinks{
-					{
-						Address: webEndpoint,
-						Text:    "Metrics",
-					},
-				},
-			}
-			landingPage, err := web.NewLandingPage(landingConfig)
-			if err != nil {
-				logger.Error("Error creating landing page", "err", err)
-				os.Exit(1)
+// SetCollectConfig sets collecting parameters
+// from command line arguments:
+// 'collect.keep-last-good',
+// 'collect.concurrency',
+// 'medusa.timeout'
+func SetCollectConfig(config CollectConfig) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	collectConfig = config
+}
+
+func getCollectConfig() CollectConfig {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	return collectConfig
+}
+
+// SetFilterConfig sets filters for backups
+// from configuration file 'config.file'.
+func SetFilterConfig(config FilterConfig) error {
+	if errs := config.compile(); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	filterConfig = config
+	return nil
+}
+
+func getFilterConfig() FilterConfig {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	return filterConfig
+}
+
+// NewPromEndpoint creates HTTP server for metrics and '/-/healthy' endpoints and,
+// if configuration manager is set, for '/-/ready', '/probe', '/-/reload'
+// and, if collection loop is set, '/-/collect' endpoints.
+// Requests contexts are derived from ctx, so they are canceled on shutdown.
+func NewPromEndpoint(ctx context.Context, version string, logger *slog.Logger) (*http.Server, error) {
+	if webEndpoint == "" {
+		logger.Error("Metric endpoint is empty", "endpoint", webEndpoint)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(webEndpoint, promhttp.Handler())
+	mux.Handle(healthyPath, healthyHandler())
+	if configManager != nil {
+		mux.Handle(readyPath, readyHandler(configManager, logger))
+		mux.Handle(probePath, probeHandler(configManager, logger))
+		if webEnableLifecycle {
+			mux.Handle(reloadPath, reloadHandler(configManager))
+			if collectionLoop != nil {
+				mux.Handle(collectPath, collectHandler(collectionLoop, configManager, logger))
 			}
-			http.Handle("/", landingPage)
 		}
-		server := &http.Server{
-			ReadHeaderTimeout: 5 * time.Second,
+	}
+	if webEndpoint != "/" {
+		landingConfig := web.LandingConfig{
+			Name:        "Medusa exporter",
+			Description: "Prometheus exporter for Medusa for Apache Cassandra",
+			HeaderColor: "#476b6b",
+			Version:     version,
+			Profiling:   "false",
+			Links: []web.LandingLinks{
+				{
+					Address: webEndpoint,
+					Text:    "Metrics",
+				},
+			},
 		}
-		if err := web.ListenAndServe(server, &webFlagsConfig, logger); err != nil {
-			logger.Error("Run web endpoint failed", "err", err)
-			os.Exit(1)
+		landingPage, err := web.NewLandingPage(landingConfig)
+		if err != nil {
+			return nil, fmt.Errorf("create landing page: %w", err)
 		}
-	}(logger)
+		mux.Handle("/", landingPage)
+	}
+	return &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}, nil
+}
+
+// ServePromEndpoint runs HTTP server on addresses from web flags
+// until the server is shut down, then http.ErrServerClosed is returned.
+func ServePromEndpoint(server *http.Server, logger *slog.Logger) error {
+	return web.ListenAndServe(server, &webFlagsConfig, logger)
 }
 
 // GetMedusaInfo get and parse Medusa info and set metrics
-func GetMedusaInfo(config, prefix string, logger *slog.Logger) {
+func GetMedusaInfo(ctx context.Context, targets []Target, logger *slog.Logger) {
+	getMedusaInfo(ctx, targets, logger)
+}
+
+// Get data from Medusa, set metrics and return summary for targets.
+func getMedusaInfo(ctx context.Context, targets []Target, logger *slog.Logger) []targetSummary {
 	// To calculate the time elapsed since the last completed full or differential backup.
 	currentUnixTime := time.Now().Unix()
-	lastBackups := initLastBackupStruct()
-	// The flag indicates whether it was possible to get data from the Medusa.
-	// By default, it's set to true.
-	getDataSuccessStatus := true
-	backupData, err := getInfoData(config, prefix, logger)
-	if err != nil {
-		getDataSuccessStatus = false
-		logger.Error("Get data from Medusa failed", "err", err)
-	}
-	parseBackupData, err := parseResult(backupData)
-	if err != nil {
-		getDataSuccessStatus = false
-		logger.Error("Parse JSON failed", "err", err)
-	}
-	if len(parseBackupData) == 0 {
-		logger.Warn("No backup data returned")
+	results := fetchTargets(ctx, targets, logger)
+	filters := getFilterConfig()
+	summary := make([]targetSummary, 0, len(targets))
+	for i, target := range targets {
+		summary = append(summary, summarizeTarget(target, results[i], filters))
+	}
+	// Collection is canceled on shutdown, the last snapshot is kept.
+	if errors.Is(ctx.Err(), context.Canceled) {
+		logger.Warn("Getting data from Medusa is canceled, metrics aren't updated")
+		return summary
+	}
+	// Metrics are populated in staging registry and published as a whole,
+	// so scrapes don't see partially populated metrics.
+	collectMutex.Lock()
+	defer collectMutex.Unlock()
+	hasSuccess := false
+	for i, target := range targets {
+		if removedTargets[target.Name()] {
+			// Target is removed on reload during collection.
+			deleteExporterTargetMetrics(target.Cluster, target.Prefix)
+			deleteTargetHealth(target)
+			continue
+		}
+		snapshotLabels[target.Name()] = target.Labels
+		setTargetMetrics(target, results[i], currentUnixTime, logger)
+		hasSuccess = hasSuccess || results[i].err == nil
+	}
+	publishSnapshot(logger)
+	// Snapshot without fresh data for any target doesn't make metrics younger.
+	if hasSuccess {
+		setSnapshotTime(time.Now())
+	}
+	return summary
+}
+
+// Get backup data from targets concurrently.
+// Number of concurrent targets is limited by collect concurrency.
+// Timeout is counted for each target from the start of getting its data,
+// so waiting for other targets isn't counted.
+func fetchTargets(ctx context.Context, targets []Target, logger *slog.Logger) []targetResult {
+	results := make([]targetResult, len(targets))
+	config := getCollectConfig()
+	semaphore := make(chan struct{}, max(config.Concurrency, 1))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			initExporterTargetMetrics(target.Cluster, target.Prefix)
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				// Collection is canceled before getting data for target.
+				results[i] = targetResult{err: ctx.Err()}
+				return
+			}
+			defer func() { <-semaphore }()
+			var (
+				targetCtx context.Context
+				cancel    context.CancelFunc
+			)
+			if config.Timeout > 0 {
+				targetCtx, cancel = context.WithTimeout(ctx, config.Timeout)
+			} else {
+				targetCtx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+			backups, err := fetchBackups(targetCtx, target.Source, target.Cluster, target.Prefix, setUpMetricValue, logger)
+			if err != nil {
+				logger.Error("Get data from Medusa failed", "source", target.Source.Name(), "cluster", target.Cluster, "prefix", target.Prefix, "err", err)
+			}
+			getExporterTimeoutMetrics(err, target.Cluster, target.Prefix)
+			getExporterSuccessMetrics(err == nil, time.Now().Unix(), target.Cluster, target.Prefix)
+			setTargetHealth(target, err, time.Now())
+			results[i] = targetResult{backups: backups, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Set metrics for one target.
+// Only metrics with the target cluster and prefix are replaced.
+// Must be called with collectMutex held.
+func setTargetMetrics(target Target, result targetResult, currentUnixTime int64, logger *slog.Logger) {
+	if result.err != nil && getCollectConfig().KeepLastGood {
+		logger.Warn("Keep metrics from the last successful collection", "source", target.Source.Name(), "cluster", target.Cluster, "prefix", target.Prefix)
+		// Only exporter status is updated.
+		resetTargetMetrics([]*prometheus.GaugeVec{medusaExporterStatusMetric}, target.Cluster, target.Prefix)
+		getExporterStatusMetrics(result.err, target.Cluster, target.Prefix, setUpMetricValue, logger)
+		return
+	}
+	if len(result.backups) == 0 {
+		logger.Warn("No backup data returned", "cluster", target.Cluster, "prefix", target.Prefix)
 	}
 	// Reset metrics.
-	resetMetrics()
-	getExporterStatusMetrics(getDataSuccessStatus, prefix, setUpMetricValue, logger)
-	for _, singleBackup := range parseBackupData {
-		getBackupMetrics(singleBackup, prefix, setUpMetricValue, logger)
+	resetTargetMetrics(stagingMetrics, target.Cluster, target.Prefix)
+	getTargetMetrics(target, result, currentUnixTime, setUpMetricValue, logger)
+}
+
+// Set exporter status and backup metrics for one target.
+func getTargetMetrics(target Target, result targetResult, currentUnixTime int64, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	// Errors are counted for target, they are logged by setUpMetric.
+	setUpMetricValueFun = countSetUpMetricErrors(setUpMetricValueFun, target.Cluster, target.Prefix)
+	lastBackups := initLastBackupStruct()
+	filters := getFilterConfig()
+	getExporterStatusMetrics(result.err, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+	// Backups, which match filters.
+	var backups []backup
+	for _, singleBackup := range result.backups {
+		if !filters.match(singleBackup) {
+			continue
+		}
+		backups = append(backups, singleBackup)
+		getBackupMetrics(singleBackup, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+		getBackupStallMetrics(singleBackup, target.Thresholds.Stall, currentUnixTime, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
 		// Only completed backups are considered.
 		if singleBackup.Finished > 0 {
 			lastBackups.compareLastBackups(singleBackup)
@@ -97,6 +294,28 @@ func GetMedusaInfo(config, prefix string, logger *slog.Logger) {
 	}
 	// If at least one backup (full or differential) is finished, set the metrics.
 	if lastBackups.hasFinishedBackups() {
-		getBackupLastMetrics(lastBackups, currentUnixTime, setUpMetricValue, logger)
+		getBackupLastMetrics(lastBackups, currentUnixTime, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+	}
+	// Without backup data compliance can't be evaluated and zero counters would be misleading.
+	if result.err != nil {
+		return
+	}
+	getBackupRPOMetrics(lastBackups, target.Thresholds.RPO, currentUnixTime, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+	getBackupScheduleMetrics(backups, target.Thresholds.BackupSchedule, currentUnixTime, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+	backupTypes := filters.BackupTypes
+	if len(backupTypes) == 0 {
+		backupTypes = []string{differentialLabel, fullLabel}
+	}
+	getBackupInventoryMetrics(backups, backupTypes, target.Cluster, target.Prefix, setUpMetricValueFun, logger)
+}
+
+// Return function, which sets metric value and counts errors for target.
+func countSetUpMetricErrors(setUpMetricValueFun setUpMetricValueFunType, cluster, prefix string) setUpMetricValueFunType {
+	return func(metric *prometheus.GaugeVec, value float64, labels ...string) error {
+		err := setUpMetricValueFun(metric, value, labels...)
+		if err != nil {
+			getExporterSetMetricErrorMetrics(cluster, prefix)
+		}
+		return err
 	}
 }
@@ -0,0 +1,397 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"go.yaml.in/yaml/v2"
+)
+
+// Default address of Medusa gRPC server.
+const defaultGRPCAddress = "localhost:50051"
+
+// Labels which are set by exporter and can't be used as target labels.
+var reservedLabels = []string{
+	"backup_name",
+	"backup_type",
+	"cluster",
+	"node_fqdn",
+	"outcome",
+	"prefix",
+	"reason",
+	"release_version",
+	"server_type",
+	"start_time",
+	"stop_time",
+}
+
+// ExporterConfig is exporter configuration from file 'config.file'.
+type ExporterConfig struct {
+	Targets    []TargetConfig   `yaml:"targets"`
+	Collection CollectionConfig `yaml:"collection"`
+	Filters    FilterConfig     `yaml:"filters"`
+	// Thresholds for all targets, they can be overridden for target.
+	Thresholds ThresholdConfig `yaml:"thresholds"`
+}
+
+// TargetConfig contains parameters for one target.
+type TargetConfig struct {
+	// Name of cluster, used as cluster label.
+	Cluster string `yaml:"cluster"`
+	// Medusa configuration file, used by cli and storage sources.
+	ConfigFile string `yaml:"config_file"`
+	// Prefix for shared storage.
+	Prefix string `yaml:"prefix"`
+	// Source name: cli, storage or grpc.
+	Source string `yaml:"source"`
+	// Medusa gRPC server parameters, used by grpc source.
+	GRPC GRPCConfig `yaml:"grpc"`
+	// Additional labels for all metrics of the target.
+	Labels map[string]string `yaml:"labels"`
+	// Thresholds, which override thresholds for all targets.
+	Thresholds ThresholdConfig `yaml:"thresholds"`
+}
+
+// CollectionConfig contains parameters for collecting metrics.
+type CollectionConfig struct {
+	Mode            string         `yaml:"mode"`
+	Interval        model.Duration `yaml:"interval"`
+	MinInterval     model.Duration `yaml:"min_interval"`
+	Concurrency     int            `yaml:"concurrency"`
+	KeepLastGood    bool           `yaml:"keep_last_good"`
+	Timeout         model.Duration `yaml:"timeout"`
+	Retries         int            `yaml:"retries"`
+	RetryBackoff    model.Duration `yaml:"retry_backoff"`
+	RetryMaxBackoff model.Duration `yaml:"retry_max_backoff"`
+	// Max age of metrics snapshot for readiness, 0 disables the check.
+	ReadyMaxAge model.Duration `yaml:"ready_max_age"`
+	// Cron expression for collections in interval mode, replaces interval.
+	Schedule string `yaml:"schedule"`
+	// Max random delay added to each scheduled collection.
+	Jitter model.Duration `yaml:"jitter"`
+	// Time zone for schedule and windows, local time zone is used if empty.
+	TimeZone string `yaml:"timezone"`
+	// Daily time windows with own collection interval.
+	Windows []CollectionWindow `yaml:"windows"`
+}
+
+// CollectionWindow contains daily time window, within which
+// collections are made every window interval.
+type CollectionWindow struct {
+	// Start and end time of day in 'HH:MM' format.
+	// Window, which ends before its start, ends on the next day.
+	Start    string         `yaml:"start"`
+	End      string         `yaml:"end"`
+	Interval model.Duration `yaml:"interval"`
+}
+
+// ThresholdConfig contains thresholds for backup compliance metrics.
+type ThresholdConfig struct {
+	// Recovery point objective by backup type: max age of the last completed backup.
+	RPO map[string]model.Duration `yaml:"rpo"`
+	// Expected schedule of backups.
+	BackupSchedule BackupScheduleConfig `yaml:"backup_schedule"`
+	// Time since start, after which unfinished backup is stalled, 0 disables the check.
+	Stall model.Duration `yaml:"stall"`
+}
+
+// BackupScheduleConfig contains expected schedule of backups,
+// which is compared with start times of backups within lookback window.
+type BackupScheduleConfig struct {
+	// Cron expressions of expected backup starts by backup type.
+	Cron map[string]string `yaml:"cron"`
+	// Max delay of backup start after expected start, 1h is used if 0.
+	Grace model.Duration `yaml:"grace"`
+	// Time window for comparing, 7d is used if 0.
+	Lookback model.Duration `yaml:"lookback"`
+	// Time zone for cron expressions, local time zone is used if empty.
+	TimeZone string `yaml:"timezone"`
+}
+
+// FilterConfig contains parameters for filtering backups.
+// Metrics are set only for backups which match all filters.
+type FilterConfig struct {
+	// Backup types, all types are used if empty.
+	BackupTypes []string `yaml:"backup_types"`
+	// Regular expressions for backup name.
+	BackupNameInclude string `yaml:"backup_name_include"`
+	BackupNameExclude string `yaml:"backup_name_exclude"`
+	include           *regexp.Regexp
+	exclude           *regexp.Regexp
+}
+
+// UnmarshalYAML sets default values for target.
+func (c *TargetConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain TargetConfig
+	*c = TargetConfig{
+		Source: CLISource,
+		GRPC:   GRPCConfig{Address: defaultGRPCAddress},
+	}
+	return unmarshal((*plain)(c))
+}
+
+// LoadConfig reads exporter configuration from file.
+// Values from file replace values in config, other values are kept.
+// Unknown fields are treated as errors.
+func LoadConfig(file string, config *ExporterConfig) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	// Empty file is valid configuration without values.
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+	return nil
+}
+
+// Validate checks configuration values.
+// All found errors are returned.
+func (c *ExporterConfig) Validate() error {
+	var errs []error
+	if len(c.Targets) == 0 {
+		errs = append(errs, errors.New("targets: at least one target is required"))
+	}
+	targetNames := make(map[string]bool, len(c.Targets))
+	for i, target := range c.Targets {
+		for _, err := range target.validate() {
+			errs = append(errs, fmt.Errorf("targets[%d]: %w", i, err))
+		}
+		name := Target{Cluster: target.Cluster, Prefix: target.Prefix}.Name()
+		if targetNames[name] {
+			errs = append(errs, fmt.Errorf("targets[%d]: duplicate target %q", i, name))
+		}
+		targetNames[name] = true
+	}
+	for _, err := range c.Collection.validate() {
+		errs = append(errs, fmt.Errorf("collection: %w", err))
+	}
+	for _, err := range c.Filters.compile() {
+		errs = append(errs, fmt.Errorf("filters: %w", err))
+	}
+	for _, err := range c.Thresholds.validate() {
+		errs = append(errs, fmt.Errorf("thresholds: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (c TargetConfig) validate() []error {
+	var errs []error
+	if strings.Contains(c.Cluster, "/") {
+		errs = append(errs, fmt.Errorf("cluster: name %q contains '/'", c.Cluster))
+	}
+	switch c.Source {
+	case CLISource, StorageSource:
+	case GRPCSource:
+		if c.GRPC.Address == "" {
+			errs = append(errs, errors.New("grpc.address: address is required for grpc source"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("source: unknown source %q, want one of: %s, %s, %s", c.Source, CLISource, StorageSource, GRPCSource))
+	}
+	for _, err := range c.Thresholds.validate() {
+		errs = append(errs, fmt.Errorf("thresholds: %w", err))
+	}
+	for name := range c.Labels {
+		switch {
+		case !model.LabelName(name).IsValidLegacy():
+			errs = append(errs, fmt.Errorf("labels: invalid label name %q", name))
+		case strings.HasPrefix(name, "__"):
+			errs = append(errs, fmt.Errorf("labels: label name %q is reserved for internal use", name))
+		case slices.Contains(reservedLabels, name):
+			errs = append(errs, fmt.Errorf("labels: label name %q is set by exporter", name))
+		}
+	}
+	return errs
+}
+
+func (c CollectionConfig) validate() []error {
+	var errs []error
+	if c.Mode != IntervalMode && c.Mode != ScrapeMode {
+		errs = append(errs, fmt.Errorf("mode: unknown mode %q, want one of: %s, %s", c.Mode, IntervalMode, ScrapeMode))
+	}
+	if time.Duration(c.Interval) < time.Second {
+		errs = append(errs, fmt.Errorf("interval: value %s is less than 1s", c.Interval))
+	}
+	if c.MinInterval < 0 {
+		errs = append(errs, fmt.Errorf("min_interval: negative value %s", c.MinInterval))
+	}
+	if c.Concurrency < 1 {
+		errs = append(errs, fmt.Errorf("concurrency: value %d is less than 1", c.Concurrency))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout: negative value %s", c.Timeout))
+	}
+	if c.Retries < 0 {
+		errs = append(errs, fmt.Errorf("retries: negative value %d", c.Retries))
+	}
+	if c.RetryBackoff < 0 {
+		errs = append(errs, fmt.Errorf("retry_backoff: negative value %s", c.RetryBackoff))
+	}
+	if c.RetryMaxBackoff < 0 {
+		errs = append(errs, fmt.Errorf("retry_max_backoff: negative value %s", c.RetryMaxBackoff))
+	}
+	if c.ReadyMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("ready_max_age: negative value %s", c.ReadyMaxAge))
+	}
+	_, scheduleErrs := newCollectionSchedule(c)
+	errs = append(errs, scheduleErrs...)
+	return errs
+}
+
+func (c ThresholdConfig) validate() []error {
+	var errs []error
+	for _, backupType := range slices.Sorted(maps.Keys(c.RPO)) {
+		if backupType != fullLabel && backupType != differentialLabel {
+			errs = append(errs, fmt.Errorf("rpo: unknown backup type %q, want one of: %s, %s", backupType, fullLabel, differentialLabel))
+		}
+		if c.RPO[backupType] <= 0 {
+			errs = append(errs, fmt.Errorf("rpo: %s: value %s is not positive", backupType, c.RPO[backupType]))
+		}
+	}
+	for _, err := range c.BackupSchedule.validate() {
+		errs = append(errs, fmt.Errorf("backup_schedule: %w", err))
+	}
+	if c.Stall < 0 {
+		errs = append(errs, fmt.Errorf("stall: negative value %s", c.Stall))
+	}
+	return errs
+}
+
+func (c BackupScheduleConfig) validate() []error {
+	var errs []error
+	for _, backupType := range slices.Sorted(maps.Keys(c.Cron)) {
+		if backupType != fullLabel && backupType != differentialLabel {
+			errs = append(errs, fmt.Errorf("cron: unknown backup type %q, want one of: %s, %s", backupType, fullLabel, differentialLabel))
+		}
+		if _, err := parseCron(c.Cron[backupType]); err != nil {
+			errs = append(errs, fmt.Errorf("cron: %s: %w", backupType, err))
+		}
+	}
+	if c.Grace < 0 {
+		errs = append(errs, fmt.Errorf("grace: negative value %s", c.Grace))
+	}
+	if c.Lookback < 0 {
+		errs = append(errs, fmt.Errorf("lookback: negative value %s", c.Lookback))
+	}
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			errs = append(errs, fmt.Errorf("timezone: %w", err))
+		}
+	}
+	return errs
+}
+
+// Return thresholds, where thresholds of target override thresholds of c.
+func (c ThresholdConfig) merge(target ThresholdConfig) ThresholdConfig {
+	merged := ThresholdConfig{
+		RPO: mergeMaps(c.RPO, target.RPO),
+		BackupSchedule: BackupScheduleConfig{
+			Cron:     mergeMaps(c.BackupSchedule.Cron, target.BackupSchedule.Cron),
+			Grace:    cmp.Or(target.BackupSchedule.Grace, c.BackupSchedule.Grace),
+			Lookback: cmp.Or(target.BackupSchedule.Lookback, c.BackupSchedule.Lookback),
+			TimeZone: cmp.Or(target.BackupSchedule.TimeZone, c.BackupSchedule.TimeZone),
+		},
+		Stall: cmp.Or(target.Stall, c.Stall),
+	}
+	return merged
+}
+
+// Return map with values of both maps, values of override replace values of base.
+// Nil is returned if both maps are empty.
+func mergeMaps[V any](base, override map[string]V) map[string]V {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]V, len(base)+len(override))
+	maps.Copy(merged, base)
+	maps.Copy(merged, override)
+	return merged
+}
+
+// Check values and compile regular expressions.
+func (c *FilterConfig) compile() []error {
+	var errs []error
+	for _, backupType := range c.BackupTypes {
+		if backupType != fullLabel && backupType != differentialLabel {
+			errs = append(errs, fmt.Errorf("backup_types: unknown backup type %q, want one of: %s, %s", backupType, fullLabel, differentialLabel))
+		}
+	}
+	var err error
+	c.include, c.exclude = nil, nil
+	if c.BackupNameInclude != "" {
+		if c.include, err = regexp.Compile("^(?:" + c.BackupNameInclude + ")$"); err != nil {
+			errs = append(errs, fmt.Errorf("backup_name_include: %w", err))
+		}
+	}
+	if c.BackupNameExclude != "" {
+		if c.exclude, err = regexp.Compile("^(?:" + c.BackupNameExclude + ")$"); err != nil {
+			errs = append(errs, fmt.Errorf("backup_name_exclude: %w", err))
+		}
+	}
+	return errs
+}
+
+// Return true if backup matches all filters.
+func (c FilterConfig) match(backupData backup) bool {
+	if len(c.BackupTypes) > 0 && !slices.Contains(c.BackupTypes, backupData.BackupType) {
+		return false
+	}
+	if c.include != nil && !c.include.MatchString(backupData.Name) {
+		return false
+	}
+	if c.exclude != nil && c.exclude.MatchString(backupData.Name) {
+		return false
+	}
+	return true
+}
+
+// NewTargets creates targets with backup sources from configuration.
+// If creating of one source fails, already created sources are closed.
+func NewTargets(configs []TargetConfig, logger *slog.Logger) ([]Target, error) {
+	targets := make([]Target, 0, len(configs))
+	for _, config := range configs {
+		source, err := NewBackupSource(
+			SourceConfig{
+				Source:     config.Source,
+				Cluster:    config.Cluster,
+				ConfigFile: config.ConfigFile,
+				Prefix:     config.Prefix,
+				GRPC:       config.GRPC,
+			},
+			logger,
+		)
+		if err != nil {
+			CloseTargets(targets)
+			return nil, fmt.Errorf("target %q: %w", Target{Cluster: config.Cluster, Prefix: config.Prefix}.Name(), err)
+		}
+		targets = append(targets, Target{
+			Cluster:    config.Cluster,
+			Prefix:     config.Prefix,
+			Labels:     config.Labels,
+			Thresholds: config.Thresholds,
+			Source:     source,
+		})
+	}
+	return targets, nil
+}
+
+// CloseTargets closes backup sources of targets.
+func CloseTargets(targets []Target) {
+	for _, target := range targets {
+		target.Source.Close()
+	}
+}
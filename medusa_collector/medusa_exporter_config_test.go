@@ -0,0 +1,474 @@
+package medusa_collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func TestLoadConfig(t *testing.T) {
+	defaultConfig := ExporterConfig{
+		Targets: []TargetConfig{{Source: CLISource}},
+		Collection: CollectionConfig{
+			Mode:        IntervalMode,
+			Interval:    model.Duration(600 * time.Second),
+			Concurrency: 4,
+		},
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    ExporterConfig
+		wantErr string
+	}{
+		{"EmptyFile", "", defaultConfig, ""},
+		{
+			"FullConfig",
+			`targets:
+  - cluster: prod
+    config_file: /etc/medusa/prod.ini
+    prefix: cluster1
+    labels:
+      env: production
+    thresholds:
+      rpo:
+        full: 26h
+  - cluster: dev
+    source: grpc
+    grpc:
+      address: medusa:50051
+      tls: true
+collection:
+  mode: scrape
+  interval: 5m
+  min_interval: 1m
+  concurrency: 2
+  keep_last_good: true
+  timeout: 2m
+  retries: 3
+  retry_backoff: 10s
+  retry_max_backoff: 2m
+  ready_max_age: 1h
+  schedule: "0 * * * *"
+  jitter: 30s
+  timezone: Europe/Berlin
+  windows:
+    - start: "01:00"
+      end: "04:00"
+      interval: 5m
+filters:
+  backup_types: [full]
+  backup_name_exclude: "tmp_.*"
+thresholds:
+  rpo:
+    full: 8d
+    differential: 1d
+  backup_schedule:
+    cron:
+      full: "0 1 * * 0"
+    grace: 2h
+    lookback: 14d
+    timezone: UTC
+  stall: 12h
+`,
+			ExporterConfig{
+				Targets: []TargetConfig{
+					{
+						Cluster:    "prod",
+						ConfigFile: "/etc/medusa/prod.ini",
+						Prefix:     "cluster1",
+						Source:     CLISource,
+						GRPC:       GRPCConfig{Address: defaultGRPCAddress},
+						Labels:     map[string]string{"env": "production"},
+						Thresholds: ThresholdConfig{RPO: map[string]model.Duration{fullLabel: model.Duration(26 * time.Hour)}},
+					},
+					{
+						Cluster: "dev",
+						Source:  GRPCSource,
+						GRPC:    GRPCConfig{Address: "medusa:50051", TLS: true},
+					},
+				},
+				Collection: CollectionConfig{
+					Mode:            ScrapeMode,
+					Interval:        model.Duration(5 * time.Minute),
+					MinInterval:     model.Duration(time.Minute),
+					Concurrency:     2,
+					KeepLastGood:    true,
+					Timeout:         model.Duration(2 * time.Minute),
+					Retries:         3,
+					RetryBackoff:    model.Duration(10 * time.Second),
+					RetryMaxBackoff: model.Duration(2 * time.Minute),
+					ReadyMaxAge:     model.Duration(time.Hour),
+					Schedule:        "0 * * * *",
+					Jitter:          model.Duration(30 * time.Second),
+					TimeZone:        "Europe/Berlin",
+					Windows:         []CollectionWindow{{Start: "01:00", End: "04:00", Interval: model.Duration(5 * time.Minute)}},
+				},
+				Filters: FilterConfig{
+					BackupTypes:       []string{fullLabel},
+					BackupNameExclude: "tmp_.*",
+				},
+				Thresholds: ThresholdConfig{
+					RPO: map[string]model.Duration{
+						fullLabel:         model.Duration(8 * 24 * time.Hour),
+						differentialLabel: model.Duration(24 * time.Hour),
+					},
+					BackupSchedule: BackupScheduleConfig{
+						Cron:     map[string]string{fullLabel: "0 1 * * 0"},
+						Grace:    model.Duration(2 * time.Hour),
+						Lookback: model.Duration(14 * 24 * time.Hour),
+						TimeZone: "UTC",
+					},
+					Stall: model.Duration(12 * time.Hour),
+				},
+			},
+			"",
+		},
+		{
+			"OnlyCollection",
+			"collection:\n  retries: 0\n",
+			ExporterConfig{
+				Targets: defaultConfig.Targets,
+				Collection: CollectionConfig{
+					Mode:        IntervalMode,
+					Interval:    model.Duration(600 * time.Second),
+					Concurrency: 4,
+				},
+			},
+			"",
+		},
+		{"UnknownField", "collection:\n  intervals: 5m\n", ExporterConfig{}, "field intervals not found"},
+		{"InvalidDuration", "collection:\n  timeout: 5\n", ExporterConfig{}, "not a valid duration string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "medusa_exporter.yml")
+			if err := os.WriteFile(file, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got := defaultConfig
+			err := LoadConfig(file, &got)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("\nVariables do not match:\ngot error: %v\nwant error: %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+	if err := LoadConfig(filepath.Join(t.TempDir(), "not_exist.yml"), &ExporterConfig{}); err == nil {
+		t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: true", err)
+	}
+}
+
+func TestExporterConfigValidate(t *testing.T) {
+	validCollection := CollectionConfig{
+		Mode:        IntervalMode,
+		Interval:    model.Duration(time.Minute),
+		Concurrency: 1,
+	}
+	validTargets := []TargetConfig{{Source: CLISource}}
+	tests := []struct {
+		name     string
+		config   ExporterConfig
+		wantErrs []string
+	}{
+		{"Valid", ExporterConfig{Targets: validTargets, Collection: validCollection}, nil},
+		{"NoTargets", ExporterConfig{Collection: validCollection}, []string{"targets: at least one target is required"}},
+		{
+			"InvalidTargets",
+			ExporterConfig{
+				Targets: []TargetConfig{
+					{Cluster: "prod/1", Source: "unknown"},
+					{Source: GRPCSource},
+					{Source: CLISource, Labels: map[string]string{"prefix": "a", "1env": "b", "__env": "c", "env": "d"}},
+					{Source: CLISource},
+				},
+				Collection: validCollection,
+			},
+			[]string{
+				`targets[0]: cluster: name "prod/1" contains '/'`,
+				`targets[0]: source: unknown source "unknown"`,
+				`targets[1]: grpc.address: address is required for grpc source`,
+				`targets[2]: labels: label name "prefix" is set by exporter`,
+				`targets[2]: labels: invalid label name "1env"`,
+				`targets[2]: labels: label name "__env" is reserved for internal use`,
+				`targets[3]: duplicate target "default/no-prefix"`,
+			},
+		},
+		{
+			"InvalidCollection",
+			ExporterConfig{
+				Targets: validTargets,
+				Collection: CollectionConfig{
+					Mode:            "cron",
+					MinInterval:     model.Duration(-time.Second),
+					Timeout:         model.Duration(-time.Second),
+					Retries:         -1,
+					RetryBackoff:    model.Duration(-time.Second),
+					RetryMaxBackoff: model.Duration(-time.Second),
+				},
+			},
+			[]string{
+				`collection: mode: unknown mode "cron"`,
+				"collection: interval: value 0s is less than 1s",
+				"collection: min_interval: negative value",
+				"collection: concurrency: value 0 is less than 1",
+				"collection: timeout: negative value",
+				"collection: retries: negative value -1",
+				"collection: retry_backoff: negative value",
+				"collection: retry_max_backoff: negative value",
+			},
+		},
+		{
+			"InvalidSchedule",
+			ExporterConfig{
+				Targets: validTargets,
+				Collection: CollectionConfig{
+					Mode:        IntervalMode,
+					Interval:    model.Duration(time.Minute),
+					Concurrency: 1,
+					Schedule:    "0 25 * * *",
+					Jitter:      model.Duration(-time.Second),
+					TimeZone:    "Mars/Olympus",
+					Windows: []CollectionWindow{
+						{Start: "1am", End: "04:00", Interval: model.Duration(5 * time.Minute)},
+						{Start: "01:00", End: "01:00"},
+					},
+				},
+			},
+			[]string{
+				`collection: schedule: hour: range "25" is out of 0-23`,
+				"collection: jitter: negative value",
+				"collection: timezone: unknown time zone Mars/Olympus",
+				`collection: windows[0]: start: invalid time "1am", want HH:MM`,
+				"collection: windows[1]: end: value 01:00 is equal to start",
+				"collection: windows[1]: interval: value 0s is less than 1s",
+			},
+		},
+		{
+			"InvalidThresholds",
+			ExporterConfig{
+				Targets: []TargetConfig{
+					{Source: CLISource, Thresholds: ThresholdConfig{RPO: map[string]model.Duration{fullLabel: 0}}},
+				},
+				Collection: validCollection,
+				Thresholds: ThresholdConfig{
+					RPO: map[string]model.Duration{"incremental": model.Duration(time.Hour)},
+					BackupSchedule: BackupScheduleConfig{
+						Cron:     map[string]string{fullLabel: "0 1 * *", "incremental": "@daily"},
+						Grace:    model.Duration(-time.Hour),
+						Lookback: model.Duration(-time.Hour),
+						TimeZone: "Mars/Olympus",
+					},
+					Stall: model.Duration(-time.Hour),
+				},
+			},
+			[]string{
+				"targets[0]: thresholds: rpo: full: value 0s is not positive",
+				`thresholds: rpo: unknown backup type "incremental"`,
+				`thresholds: backup_schedule: cron: full: expression "0 1 * *" has 4 fields, want 5`,
+				`thresholds: backup_schedule: cron: unknown backup type "incremental"`,
+				"thresholds: backup_schedule: grace: negative value",
+				"thresholds: backup_schedule: lookback: negative value",
+				"thresholds: backup_schedule: timezone: unknown time zone Mars/Olympus",
+				"thresholds: stall: negative value",
+			},
+		},
+		{
+			"InvalidFilters",
+			ExporterConfig{
+				Targets:    validTargets,
+				Collection: validCollection,
+				Filters:    FilterConfig{BackupTypes: []string{"incremental"}, BackupNameInclude: "(", BackupNameExclude: "["},
+			},
+			[]string{
+				`filters: backup_types: unknown backup type "incremental"`,
+				"filters: backup_name_include: error parsing regexp",
+				"filters: backup_name_exclude: error parsing regexp",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErrs)
+			}
+			for _, wantErr := range tt.wantErrs {
+				if !strings.Contains(err.Error(), wantErr) {
+					t.Errorf("\nError is not found:\n%s\nin:\n%v", wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+func TestThresholdConfigMerge(t *testing.T) {
+	global := ThresholdConfig{
+		RPO: map[string]model.Duration{
+			fullLabel:         model.Duration(7 * 24 * time.Hour),
+			differentialLabel: model.Duration(24 * time.Hour),
+		},
+		BackupSchedule: BackupScheduleConfig{
+			Cron:     map[string]string{fullLabel: "0 1 * * 0"},
+			Grace:    model.Duration(time.Hour),
+			TimeZone: "UTC",
+		},
+		Stall: model.Duration(6 * time.Hour),
+	}
+	tests := []struct {
+		name   string
+		global ThresholdConfig
+		target ThresholdConfig
+		want   ThresholdConfig
+	}{
+		{"Empty", ThresholdConfig{}, ThresholdConfig{}, ThresholdConfig{}},
+		{"OnlyGlobal", global, ThresholdConfig{}, global},
+		{
+			"TargetOverride",
+			global,
+			ThresholdConfig{
+				RPO: map[string]model.Duration{fullLabel: model.Duration(26 * time.Hour)},
+				BackupSchedule: BackupScheduleConfig{
+					Cron:     map[string]string{differentialLabel: "0 1 * * 1-6"},
+					Lookback: model.Duration(24 * time.Hour),
+				},
+				Stall: model.Duration(12 * time.Hour),
+			},
+			ThresholdConfig{
+				RPO: map[string]model.Duration{
+					fullLabel:         model.Duration(26 * time.Hour),
+					differentialLabel: model.Duration(24 * time.Hour),
+				},
+				BackupSchedule: BackupScheduleConfig{
+					Cron:     map[string]string{fullLabel: "0 1 * * 0", differentialLabel: "0 1 * * 1-6"},
+					Grace:    model.Duration(time.Hour),
+					Lookback: model.Duration(24 * time.Hour),
+					TimeZone: "UTC",
+				},
+				Stall: model.Duration(12 * time.Hour),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.global.merge(tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+	if global.RPO[fullLabel] != model.Duration(7*24*time.Hour) {
+		t.Errorf("\nVariables do not match:\ngot: %+v\nwant: global thresholds aren't changed", global)
+	}
+}
+
+func TestFilterConfigMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterConfig
+		want   []bool
+	}{
+		{"NoFilters", FilterConfig{}, []bool{true, true, true}},
+		{"BackupTypes", FilterConfig{BackupTypes: []string{differentialLabel}}, []bool{false, true, true}},
+		{"Include", FilterConfig{BackupNameInclude: "daily_.*"}, []bool{true, true, false}},
+		{"Exclude", FilterConfig{BackupNameExclude: "manual"}, []bool{true, true, false}},
+		{"IncludeFullMatch", FilterConfig{BackupNameInclude: "daily"}, []bool{false, false, false}},
+		{"AllFilters", FilterConfig{BackupTypes: []string{fullLabel}, BackupNameInclude: "daily_.*", BackupNameExclude: ".*_2"}, []bool{true, false, false}},
+	}
+	backups := []backup{
+		{Name: "daily_1", BackupType: fullLabel},
+		{Name: "daily_2", BackupType: differentialLabel},
+		{Name: "manual", BackupType: differentialLabel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.filter.compile(); len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			got := make([]bool, 0, len(backups))
+			for _, b := range backups {
+				got = append(got, tt.filter.match(b))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTargets(t *testing.T) {
+	configs := []TargetConfig{
+		{Cluster: "prod", Prefix: "cluster1", Source: CLISource, Labels: map[string]string{"env": "production"}},
+		{Source: GRPCSource, GRPC: GRPCConfig{Address: "localhost:50051"}},
+	}
+	targets, err := NewTargets(configs, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CloseTargets(targets)
+	got := make([]string, 0, len(targets))
+	for _, target := range targets {
+		got = append(got, target.Name()+" "+target.Source.Name())
+	}
+	want := []string{"prod/cluster1 cli", "default/no-prefix grpc"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, want)
+	}
+	if !reflect.DeepEqual(targets[0].Labels, configs[0].Labels) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", targets[0].Labels, configs[0].Labels)
+	}
+	configs = append(configs, TargetConfig{Source: GRPCSource})
+	if _, err := NewTargets(configs, logger); err == nil {
+		t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: true", err)
+	}
+}
+
+func TestGetMedusaInfoFiltersAndLabels(t *testing.T) {
+	resetStagingMetrics()
+	defer SetFilterConfig(FilterConfig{})
+	if err := SetFilterConfig(FilterConfig{BackupNameExclude: "manual_.*"}); err != nil {
+		t.Fatal(err)
+	}
+	source := &fakeSource{backups: []backup{
+		{Name: "daily_1", BackupType: fullLabel, Started: 1697711900, Finished: 1697712000},
+		{Name: "manual_1", BackupType: fullLabel, Started: 1697712900, Finished: 1697713000},
+	}}
+	GetMedusaInfo(
+		context.Background(),
+		[]Target{{Cluster: "prod", Labels: map[string]string{"env": "production"}, Source: source}},
+		logger,
+	)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(snapshot)
+	out := gatherText(t, registry)
+	wantText := []string{
+		`medusa_backup_status{backup_name="daily_1",backup_type="full",cluster="prod",env="production",prefix="no-prefix"} 0`,
+		`medusa_backup_last_duration_seconds{backup_type="full",cluster="prod",env="production",prefix="no-prefix"} 100`,
+		`medusa_exporter_status{cluster="prod",env="production",prefix="no-prefix",reason="none"} 1`,
+	}
+	for _, text := range wantText {
+		if !strings.Contains(out, text) {
+			t.Errorf("\nMetric is not found:\n%s\nin:\n%s", text, out)
+		}
+	}
+	if strings.Contains(out, "manual_1") {
+		t.Errorf("\nFiltered backup is found in:\n%s", out)
+	}
+	if err := SetFilterConfig(FilterConfig{BackupNameInclude: "("}); err == nil {
+		t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: true", err)
+	}
+}
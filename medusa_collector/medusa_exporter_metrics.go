@@ -1,38 +1,344 @@
 package medusa_collector
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
-	medusaExporterStatusMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaExporterStatusMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_exporter_status",
 		Help: "Medusa exporter get data status.",
 	},
 		[]string{
+			"cluster",
 			"prefix",
+			"reason",
 		})
+	// Counter isn't reset between collections.
+	medusaExporterTimeoutsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "medusa_exporter_timeouts_total",
+		Help: "Number of getting data from Medusa terminated by timeout.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	// Gauges below aren't reset between collections.
+	medusaExporterLastSuccessMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_last_success_timestamp_seconds",
+		Help: "Time of the last successful getting data from Medusa.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterConsecutiveFailuresMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_consecutive_failures",
+		Help: "Number of consecutive failed getting data from Medusa.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterAttemptsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "medusa_exporter_get_data_attempts_total",
+		Help: "Number of attempts to get data from Medusa, including retries.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterOutcomesMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "medusa_exporter_get_data_total",
+		Help: "Number of getting data from Medusa by final outcome after retries.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+			"outcome",
+		})
+	medusaExporterCollectionDurationMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_collection_duration_seconds",
+		Help: "Duration of the last getting data from Medusa, including retries.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterBackupsParsedMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_backups_parsed",
+		Help: "Number of backups parsed in the last getting data from Medusa.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterJSONParseErrorsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "medusa_exporter_json_parse_errors_total",
+		Help: "Number of errors of parsing JSON data from Medusa.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterSetMetricErrorsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "medusa_exporter_set_metric_errors_total",
+		Help: "Number of errors of setting metric values.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	// Metrics below are set only for cli source.
+	medusaExporterCommandDurationMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_command_duration_seconds",
+		Help: "Wall time of the last medusa command run.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterCommandExitCodeMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_command_exit_code",
+		Help: "Exit code of the last medusa command run.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterCommandStdoutBytesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_exporter_command_stdout_bytes",
+		Help: "Size of output of the last medusa command run.",
+	},
+		[]string{
+			"cluster",
+			"prefix",
+		})
+	medusaExporterConfigLastReloadSuccessfulMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "medusa_exporter_config_last_reload_successful",
+		Help: "Whether the last exporter configuration reload attempt was successful.",
+	})
+	medusaExporterConfigLastReloadSuccessMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "medusa_exporter_config_last_reload_success_timestamp_seconds",
+		Help: "Time of the last successful exporter configuration reload.",
+	})
+	medusaExporterNextCollectionMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "medusa_exporter_next_collection_timestamp_seconds",
+		Help: "Time of the next scheduled collection in interval mode.",
+	})
+)
+
+// Exporter metrics with cluster and prefix labels, which aren't reset between collections.
+var exporterTargetMetrics = []interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+}{
+	medusaExporterTimeoutsMetric,
+	medusaExporterLastSuccessMetric,
+	medusaExporterConsecutiveFailuresMetric,
+	medusaExporterAttemptsMetric,
+	medusaExporterOutcomesMetric,
+	medusaExporterCollectionDurationMetric,
+	medusaExporterBackupsParsedMetric,
+	medusaExporterJSONParseErrorsMetric,
+	medusaExporterSetMetricErrorsMetric,
+	medusaExporterCommandDurationMetric,
+	medusaExporterCommandExitCodeMetric,
+	medusaExporterCommandStdoutBytesMetric,
+}
+
+// Reasons of failed getting data from Medusa.
+const (
+	errorReason   = "error"
+	timeoutReason = "timeout"
+)
+
+// Final outcomes of getting data from Medusa.
+const (
+	successOutcome = "success"
+	failureOutcome = "failure"
 )
 
 // Set exporter metrics:
 //   - medusa_exporter_status
-func getExporterStatusMetrics(getDataStatus bool, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
-	if prefix == "" {
-		prefix = noPrefixLabel
+func getExporterStatusMetrics(getDataErr error, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	reason := noneLabel
+	switch {
+	case getDataErr == nil:
+	case errors.Is(getDataErr, context.DeadlineExceeded):
+		reason = timeoutReason
+	default:
+		reason = errorReason
 	}
 	setUpMetric(
 		medusaExporterStatusMetric,
 		"medusa_exporter_status",
-		convertBoolToFloat64(getDataStatus),
+		convertBoolToFloat64(getDataErr == nil),
 		setUpMetricValueFun,
 		logger,
-		prefix,
+		labels["cluster"],
+		labels["prefix"],
+		reason,
 	)
 }
 
-func resetExporterMetrics() {
-	medusaExporterStatusMetric.Reset()
+// Initialize exporter counters of target with 0, so rate() works from the first increment:
+//   - medusa_exporter_timeouts_total
+//   - medusa_exporter_get_data_total
+//   - medusa_exporter_json_parse_errors_total
+//   - medusa_exporter_set_metric_errors_total
+func initExporterTargetMetrics(cluster, prefix string) {
+	labels := targetLabels(cluster, prefix)
+	medusaExporterTimeoutsMetric.With(labels).Add(0)
+	medusaExporterOutcomesMetric.WithLabelValues(labels["cluster"], labels["prefix"], successOutcome).Add(0)
+	medusaExporterOutcomesMetric.WithLabelValues(labels["cluster"], labels["prefix"], failureOutcome).Add(0)
+	medusaExporterJSONParseErrorsMetric.With(labels).Add(0)
+	medusaExporterSetMetricErrorsMetric.With(labels).Add(0)
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_timeouts_total
+func getExporterTimeoutMetrics(getDataErr error, cluster, prefix string) {
+	if errors.Is(getDataErr, context.DeadlineExceeded) {
+		medusaExporterTimeoutsMetric.With(targetLabels(cluster, prefix)).Inc()
+	}
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_get_data_attempts_total
+func getExporterAttemptMetrics(cluster, prefix string) {
+	medusaExporterAttemptsMetric.With(targetLabels(cluster, prefix)).Inc()
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_get_data_total
+func getExporterOutcomeMetrics(getDataStatus bool, cluster, prefix string) {
+	labels := targetLabels(cluster, prefix)
+	outcome := failureOutcome
+	if getDataStatus {
+		outcome = successOutcome
+	}
+	medusaExporterOutcomesMetric.WithLabelValues(labels["cluster"], labels["prefix"], outcome).Inc()
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_last_success_timestamp_seconds
+//   - medusa_exporter_consecutive_failures
+func getExporterSuccessMetrics(getDataStatus bool, currentUnixTime int64, cluster, prefix string) {
+	labels := targetLabels(cluster, prefix)
+	failuresGauge := medusaExporterConsecutiveFailuresMetric.With(labels)
+	if !getDataStatus {
+		failuresGauge.Inc()
+		return
+	}
+	failuresGauge.Set(0)
+	medusaExporterLastSuccessMetric.With(labels).Set(float64(currentUnixTime))
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_collection_duration_seconds
+//   - medusa_exporter_backups_parsed
+func getExporterCollectionMetrics(duration time.Duration, backupsParsed int, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	setUpMetric(
+		medusaExporterCollectionDurationMetric,
+		"medusa_exporter_collection_duration_seconds",
+		duration.Seconds(),
+		setUpMetricValueFun,
+		logger,
+		labels["cluster"],
+		labels["prefix"],
+	)
+	setUpMetric(
+		medusaExporterBackupsParsedMetric,
+		"medusa_exporter_backups_parsed",
+		float64(backupsParsed),
+		setUpMetricValueFun,
+		logger,
+		labels["cluster"],
+		labels["prefix"],
+	)
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_json_parse_errors_total
+func getExporterParseErrorMetrics(getDataErr error, cluster, prefix string) {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	if errors.As(getDataErr, &syntaxErr) || errors.As(getDataErr, &typeErr) {
+		medusaExporterJSONParseErrorsMetric.With(targetLabels(cluster, prefix)).Inc()
+	}
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_set_metric_errors_total
+func getExporterSetMetricErrorMetrics(cluster, prefix string) {
+	medusaExporterSetMetricErrorsMetric.With(targetLabels(cluster, prefix)).Inc()
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_command_duration_seconds
+//   - medusa_exporter_command_exit_code
+//   - medusa_exporter_command_stdout_bytes
+func getExporterCommandMetrics(duration time.Duration, exitCode, stdoutBytes int, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	setUpMetric(
+		medusaExporterCommandDurationMetric,
+		"medusa_exporter_command_duration_seconds",
+		duration.Seconds(),
+		setUpMetricValueFun,
+		logger,
+		labels["cluster"],
+		labels["prefix"],
+	)
+	setUpMetric(
+		medusaExporterCommandExitCodeMetric,
+		"medusa_exporter_command_exit_code",
+		float64(exitCode),
+		setUpMetricValueFun,
+		logger,
+		labels["cluster"],
+		labels["prefix"],
+	)
+	setUpMetric(
+		medusaExporterCommandStdoutBytesMetric,
+		"medusa_exporter_command_stdout_bytes",
+		float64(stdoutBytes),
+		setUpMetricValueFun,
+		logger,
+		labels["cluster"],
+		labels["prefix"],
+	)
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_config_last_reload_successful
+//   - medusa_exporter_config_last_reload_success_timestamp_seconds
+func getExporterReloadMetrics(reloadStatus bool, currentUnixTime int64) {
+	medusaExporterConfigLastReloadSuccessfulMetric.Set(convertBoolToFloat64(reloadStatus))
+	if reloadStatus {
+		medusaExporterConfigLastReloadSuccessMetric.Set(float64(currentUnixTime))
+	}
+}
+
+// Set exporter metrics:
+//   - medusa_exporter_next_collection_timestamp_seconds
+func getExporterScheduleMetrics(nextCollection time.Time) {
+	medusaExporterNextCollectionMetric.Set(float64(nextCollection.Unix()))
+}
+
+// Delete exporter metrics of target, which isn't collected anymore.
+func deleteExporterTargetMetrics(cluster, prefix string) {
+	for _, metric := range exporterTargetMetrics {
+		metric.DeletePartialMatch(targetLabels(cluster, prefix))
+	}
 }
@@ -2,23 +2,30 @@ package medusa_collector
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
 )
 
+func resetExporterMetrics() {
+	medusaExporterStatusMetric.Reset()
+}
+
 func fakeSetUpMetricValue(metric *prometheus.GaugeVec, value float64, labels ...string) error {
 	return errors.New("fake error")
 }
 
 func TestGetExporterStatusMetrics(t *testing.T) {
 	type args struct {
-		getDataStatus       bool
+		getDataErr          error
 		prefix              string
 		testText            string
 		setUpMetricValueFun setUpMetricValueFunType
@@ -29,22 +36,33 @@ func TestGetExporterStatusMetrics(t *testing.T) {
 	}{
 		{"GetExporterStatusGood",
 			args{
-				true,
+				nil,
 				"",
 				`# HELP medusa_exporter_status Medusa exporter get data status.
 # TYPE medusa_exporter_status gauge
-medusa_exporter_status{prefix="no-prefix"} 1
+medusa_exporter_status{cluster="default",prefix="no-prefix",reason="none"} 1
 `,
 				setUpMetricValue,
 			},
 		},
 		{"GetExporterStatusBad",
 			args{
-				false,
+				errors.New("exit status 1"),
 				"",
 				`# HELP medusa_exporter_status Medusa exporter get data status.
 # TYPE medusa_exporter_status gauge
-medusa_exporter_status{prefix="no-prefix"} 0
+medusa_exporter_status{cluster="default",prefix="no-prefix",reason="error"} 0
+`,
+				setUpMetricValue,
+			},
+		},
+		{"GetExporterStatusTimeout",
+			args{
+				fmt.Errorf("medusa command terminated: %w", context.DeadlineExceeded),
+				"prod",
+				`# HELP medusa_exporter_status Medusa exporter get data status.
+# TYPE medusa_exporter_status gauge
+medusa_exporter_status{cluster="default",prefix="prod",reason="timeout"} 0
 `,
 				setUpMetricValue,
 			},
@@ -55,7 +73,7 @@ medusa_exporter_status{prefix="no-prefix"} 0
 			logOut := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(logOut, &slog.HandlerOptions{Level: slog.LevelDebug}))
 			resetExporterMetrics()
-			getExporterStatusMetrics(tt.args.getDataStatus, tt.args.prefix, tt.args.setUpMetricValueFun, lc)
+			getExporterStatusMetrics(tt.args.getDataErr, "", tt.args.prefix, tt.args.setUpMetricValueFun, lc)
 			reg := prometheus.NewRegistry()
 			reg.MustRegister(medusaExporterStatusMetric)
 			metricFamily, err := reg.Gather()
@@ -75,9 +93,49 @@ medusa_exporter_status{prefix="no-prefix"} 0
 	}
 }
 
+func TestGetExporterTimeoutMetrics(t *testing.T) {
+	tests := []struct {
+		name       string
+		getDataErr error
+		want       float64
+	}{
+		{"Success", nil, 0},
+		{"Error", errors.New("exit status 1"), 0},
+		{"Timeout", fmt.Errorf("medusa command terminated: %w", context.DeadlineExceeded), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medusaExporterTimeoutsMetric.Reset()
+			getExporterTimeoutMetrics(tt.getDataErr, "", "prod")
+			if got := getCounterValue(t, medusaExporterTimeoutsMetric.WithLabelValues(defaultClusterLabel, "prod")); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitExporterTargetMetrics(t *testing.T) {
+	counters := []*prometheus.CounterVec{
+		medusaExporterTimeoutsMetric,
+		medusaExporterOutcomesMetric,
+		medusaExporterJSONParseErrorsMetric,
+		medusaExporterSetMetricErrorsMetric,
+	}
+	for _, counter := range counters {
+		counter.Reset()
+	}
+	initExporterTargetMetrics("", "prod")
+	// Counters are exposed before the first increment, outcome counter for each outcome.
+	for i, want := range []int{1, 2, 1, 1} {
+		if got := countMetrics(counters[i]); got != want {
+			t.Errorf("\nVariables do not match:\ngot: %d metrics\nwant: %d metrics", got, want)
+		}
+	}
+}
+
 func TestGetExporterStatusErrorsAndDebugs(t *testing.T) {
 	type args struct {
-		getDataStatus       bool
+		getDataErr          error
 		prefix              string
 		setUpMetricValueFun setUpMetricValueFunType
 		errorsCount         int
@@ -89,7 +147,7 @@ func TestGetExporterStatusErrorsAndDebugs(t *testing.T) {
 	}{
 		{"GetExporterInfoLogError",
 			args{
-				true,
+				nil,
 				"",
 				fakeSetUpMetricValue,
 				1,
@@ -101,7 +159,7 @@ func TestGetExporterStatusErrorsAndDebugs(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			getExporterStatusMetrics(tt.args.getDataStatus, tt.args.prefix, tt.args.setUpMetricValueFun, lc)
+			getExporterStatusMetrics(tt.args.getDataErr, "", tt.args.prefix, tt.args.setUpMetricValueFun, lc)
 			errorsOutputCount := strings.Count(out.String(), "level=ERROR")
 			debugsOutputCount := strings.Count(out.String(), "level=DEBUG")
 			if tt.args.errorsCount != errorsOutputCount || tt.args.debugsCount != debugsOutputCount {
@@ -112,3 +170,76 @@ func TestGetExporterStatusErrorsAndDebugs(t *testing.T) {
 		})
 	}
 }
+
+func getCounterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := counter.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestGetExporterParseErrorMetrics(t *testing.T) {
+	_, syntaxErr := parseResult([]byte(`{invalid json}`))
+	_, typeErr := parseResult([]byte(`{"name":"test_backup"}`))
+	tests := []struct {
+		name       string
+		getDataErr error
+		want       float64
+	}{
+		{"NoError", nil, 0},
+		{"SyntaxError", fmt.Errorf("parse JSON: %w", syntaxErr), 1},
+		{"TypeError", fmt.Errorf("parse JSON: %w", typeErr), 1},
+		{"CommandError", errors.New("exit status 1"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medusaExporterJSONParseErrorsMetric.Reset()
+			getExporterParseErrorMetrics(tt.getDataErr, "", "")
+			if got := getCounterValue(t, medusaExporterJSONParseErrorsMetric.WithLabelValues(defaultClusterLabel, noPrefixLabel)); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountSetUpMetricErrors(t *testing.T) {
+	tests := []struct {
+		name                string
+		setUpMetricValueFun setUpMetricValueFunType
+		want                float64
+	}{
+		{"SetUpMetricGood", setUpMetricValue, 0},
+		{"SetUpMetricBad", fakeSetUpMetricValue, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetExporterMetrics()
+			medusaExporterSetMetricErrorsMetric.Reset()
+			setUpMetricValueFun := countSetUpMetricErrors(tt.setUpMetricValueFun, "", "prod")
+			getExporterStatusMetrics(nil, "", "prod", setUpMetricValueFun, logger)
+			if got := getCounterValue(t, medusaExporterSetMetricErrorsMetric.WithLabelValues(defaultClusterLabel, "prod")); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetExporterCollectionMetrics(t *testing.T) {
+	medusaExporterCollectionDurationMetric.Reset()
+	medusaExporterBackupsParsedMetric.Reset()
+	getExporterCollectionMetrics(1500*time.Millisecond, 3, "", "prod", setUpMetricValue, logger)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(medusaExporterCollectionDurationMetric, medusaExporterBackupsParsedMetric)
+	want := `# HELP medusa_exporter_backups_parsed Number of backups parsed in the last getting data from Medusa.
+# TYPE medusa_exporter_backups_parsed gauge
+medusa_exporter_backups_parsed{cluster="default",prefix="prod"} 3
+# HELP medusa_exporter_collection_duration_seconds Duration of the last getting data from Medusa, including retries.
+# TYPE medusa_exporter_collection_duration_seconds gauge
+medusa_exporter_collection_duration_seconds{cluster="default",prefix="prod"} 1.5
+`
+	if got := gatherText(t, reg); got != want {
+		t.Errorf("\nVariables do not match:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
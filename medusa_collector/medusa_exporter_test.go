@@ -2,14 +2,21 @@ package medusa_collector
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/exporter-toolkit/web"
 )
 
@@ -48,6 +55,102 @@ func TestSetPromPortAndPath(t *testing.T) {
 	}
 }
 
+func TestNewPromEndpoint(t *testing.T) {
+	defer func() {
+		SetPromPortAndPath(web.FlagConfig{}, "")
+		SetConfigManager(nil)
+		SetEnableLifecycle(false)
+		SetCollectionLoop(nil)
+	}()
+	tests := []struct {
+		name            string
+		enableLifecycle bool
+		method          string
+		path            string
+		wantCode        int
+	}{
+		{"Metrics", false, http.MethodGet, "/metrics", http.StatusOK},
+		{"Healthy", false, http.MethodGet, "/-/healthy", http.StatusOK},
+		{"NotReady", false, http.MethodGet, "/-/ready", http.StatusServiceUnavailable},
+		{"LandingPage", false, http.MethodGet, "/", http.StatusOK},
+		{"ProbeWithoutTarget", false, http.MethodGet, "/probe", http.StatusBadRequest},
+		{"ReloadDisabled", false, http.MethodPost, "/-/reload", http.StatusNotFound},
+		{"ReloadWrongMethod", true, http.MethodGet, "/-/reload", http.StatusMethodNotAllowed},
+		{"CollectDisabled", false, http.MethodPost, "/-/collect", http.StatusNotFound},
+		{"CollectWithoutTargets", true, http.MethodPost, "/-/collect", http.StatusNotFound},
+		{"CollectWrongMethod", true, http.MethodGet, "/-/collect", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetPromPortAndPath(web.FlagConfig{}, "/metrics")
+			manager := newTestConfigManager(nil, CollectionConfig{})
+			SetConfigManager(manager)
+			SetCollectionLoop(NewCollectionLoop(manager, logger))
+			SetEnableLifecycle(tt.enableLifecycle)
+			server, err := NewPromEndpoint(context.Background(), "test", logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			server.Handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServePromEndpointShutdown(t *testing.T) {
+	defer SetPromPortAndPath(web.FlagConfig{}, "")
+	SetPromPortAndPath(web.FlagConfig{
+		WebListenAddresses: &([]string{"127.0.0.1:0"}),
+		WebSystemdSocket:   func(i bool) *bool { return &i }(false),
+		WebConfigFile:      func(i string) *string { return &i }(""),
+	}, "/metrics")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, err := NewPromEndpoint(ctx, "test", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverErrs := make(chan error, 1)
+	go func() {
+		serverErrs <- ServePromEndpoint(server, logger)
+	}()
+	// Wait for listener.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-serverErrs:
+		if !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", err, http.ErrServerClosed)
+		}
+	case <-shutdownCtx.Done():
+		t.Errorf("\nServer is not stopped after shutdown")
+	}
+}
+
+func TestGetMedusaInfoCanceled(t *testing.T) {
+	resetStagingMetrics()
+	source := &fakeSource{backups: testIndexBackups()}
+	targets := []Target{{Cluster: "canceled", Source: source}}
+	GetMedusaInfo(context.Background(), targets, logger)
+	want := countMetrics(snapshot)
+	// Collection is canceled on shutdown.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	source.errs = []error{context.Canceled}
+	GetMedusaInfo(ctx, targets, logger)
+	if got := countMetrics(snapshot); got != want {
+		t.Errorf("\nVariables do not match:\ngot: %d metrics\nwant: %d metrics", got, want)
+	}
+}
+
 func TestGetMedusaInfoCommand(t *testing.T) {
 	type args struct {
 		config string
@@ -121,7 +224,7 @@ func TestGetMedusaInfoCommand(t *testing.T) {
 				"",
 				0,
 			},
-			`level=ERROR msg="Parse JSON failed"`,
+			`level=ERROR msg="Get data from Medusa failed" source=cli cluster="" prefix="" err="parse JSON: invalid character`,
 		},
 		{
 			"GetMedusaInfoEmptyBackupList",
@@ -136,15 +239,13 @@ func TestGetMedusaInfoCommand(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resetMetrics()
+			resetStagingMetrics()
 			mockData = tt.mockTestData
-			execCommand = fakeExecCommand
-			defer func() { execCommand = exec.Command }()
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
 			GetMedusaInfo(
-				tt.args.config,
-				tt.args.prefix,
+				context.Background(),
+				[]Target{{Prefix: tt.args.prefix, Source: newCLISource("", tt.args.config, tt.args.prefix, fakeExecCommand, lc)}},
 				lc,
 			)
 			if !strings.Contains(out.String(), tt.testText) {
@@ -154,10 +255,10 @@ func TestGetMedusaInfoCommand(t *testing.T) {
 	}
 }
 
-func fakeExecCommand(command string, args ...string) *exec.Cmd {
+func fakeExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestExecCommandHelper", "--", command}
 	cs = append(cs, args...)
-	cmd := exec.Command(os.Args[0], cs...)
+	cmd := exec.CommandContext(ctx, os.Args[0], cs...)
 	es := strconv.Itoa(mockData.mockExit)
 	cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1",
 		"STDOUT=" + mockData.mockStdout,
@@ -166,10 +267,30 @@ func fakeExecCommand(command string, args ...string) *exec.Cmd {
 	return cmd
 }
 
+// Command hangs until it's killed.
+func fakeHangExecCommand(ctx context.Context, command string, args ...string) *exec.Cmd {
+	cmd := fakeExecCommand(ctx, command, args...)
+	cmd.Env = append(cmd.Env, "HANG=parent")
+	return cmd
+}
+
 func TestExecCommandHelper(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
 	}
+	switch os.Getenv("HANG") {
+	case "parent":
+		// Hung medusa with child process, which holds stdout.
+		child := exec.Command(os.Args[0], "-test.run=TestExecCommandHelper")
+		child.Env = []string{"GO_WANT_HELPER_PROCESS=1", "HANG=child"}
+		child.Stdout = os.Stdout
+		if err := child.Start(); err != nil {
+			os.Exit(2)
+		}
+		time.Sleep(time.Minute)
+	case "child":
+		time.Sleep(time.Minute)
+	}
 	fmt.Fprintf(os.Stdout, "%s", os.Getenv("STDOUT"))
 	fmt.Fprintf(os.Stderr, "%s", os.Getenv("STDERR"))
 	i, _ := strconv.Atoi(os.Getenv("EXIT_STATUS"))
@@ -189,3 +310,175 @@ func getLogger() *slog.Logger {
 func ptrToVal[T any](v *T) T {
 	return *v
 }
+
+// Return number of series for collector.
+func countMetrics(collector prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		collector.Collect(ch)
+		close(ch)
+	}()
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
+
+func TestGetMedusaInfoKeepLastGood(t *testing.T) {
+	tests := []struct {
+		name         string
+		keepLastGood bool
+		wantBackups  int
+	}{
+		{"KeepLastGood", true, 1},
+		{"ResetOnFailure", false, 0},
+	}
+	defer SetCollectConfig(CollectConfig{Concurrency: 1})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetStagingMetrics()
+			medusaExporterLastSuccessMetric.Reset()
+			medusaExporterConsecutiveFailuresMetric.Reset()
+			SetCollectConfig(CollectConfig{KeepLastGood: tt.keepLastGood, Concurrency: 1})
+			errFailed := errors.New("failed")
+			// The first collection is successful, the next two fail.
+			source := &fakeSource{backups: []backup{{Name: "test_backup", BackupType: fullLabel, Finished: 1697712000}}}
+			targets := []Target{{Source: source}}
+			GetMedusaInfo(context.Background(), targets, logger)
+			if got := countMetrics(medusaBackupInfoMetric); got != 1 {
+				t.Fatalf("\nVariables do not match:\ngot: %d\nwant: %d", got, 1)
+			}
+			lastSuccess := medusaExporterLastSuccessMetric.WithLabelValues(defaultClusterLabel, noPrefixLabel)
+			if got := getGaugeValue(t, lastSuccess); got <= 0 {
+				t.Errorf("\nLast success timestamp is not set: %v", got)
+			}
+			source.errs = []error{nil, errFailed, errFailed}
+			GetMedusaInfo(context.Background(), targets, logger)
+			GetMedusaInfo(context.Background(), targets, logger)
+			if got := countMetrics(medusaBackupInfoMetric); got != tt.wantBackups {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, tt.wantBackups)
+			}
+			if got := countMetrics(medusaBackupLastDatabaseSizeMetric); got != tt.wantBackups {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, tt.wantBackups)
+			}
+			failures := medusaExporterConsecutiveFailuresMetric.WithLabelValues(defaultClusterLabel, noPrefixLabel)
+			if got := getGaugeValue(t, failures); got != 2 {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, 2)
+			}
+			// Exporter status is updated in both modes.
+			if got := countMetrics(medusaExporterStatusMetric); got != 1 ||
+				getGaugeValue(t, medusaExporterStatusMetric.WithLabelValues(defaultClusterLabel, noPrefixLabel, errorReason)) != 0 {
+				t.Errorf("\nExporter status is not updated on failure")
+			}
+			// Successful collection resets failures.
+			source.errs = nil
+			GetMedusaInfo(context.Background(), targets, logger)
+			if got := getGaugeValue(t, failures); got != 0 {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, 0)
+			}
+		})
+	}
+}
+
+func TestFetchTargetsTimeout(t *testing.T) {
+	defer SetCollectConfig(CollectConfig{Concurrency: 1})
+	SetCollectConfig(CollectConfig{Concurrency: 1, Timeout: 200 * time.Millisecond})
+	var targets []Target
+	for _, prefix := range []string{"a", "b", "c", "d"} {
+		targets = append(targets, Target{Cluster: "timeout", Prefix: prefix, Source: &fakeSource{backups: testIndexBackups(), delay: 100 * time.Millisecond}})
+	}
+	// Targets are collected one by one longer than timeout,
+	// but timeout is counted for each target separately.
+	for i, result := range fetchTargets(context.Background(), targets, logger) {
+		if result.err != nil {
+			t.Errorf("\nVariables do not match:\ngot: %s: %v\nwant: no error", targets[i].Name(), result.err)
+		}
+	}
+	// Canceled collection doesn't wait for free slot.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i, result := range fetchTargets(ctx, targets, logger) {
+		if !errors.Is(result.err, context.Canceled) {
+			t.Errorf("\nVariables do not match:\ngot: %s: %v\nwant: %v", targets[i].Name(), result.err, context.Canceled)
+		}
+	}
+}
+
+func TestGetMedusaInfoMultiplePrefixes(t *testing.T) {
+	resetStagingMetrics()
+	defer SetCollectConfig(CollectConfig{Concurrency: 1})
+	SetCollectConfig(CollectConfig{Concurrency: 2})
+	prod := &fakeSource{backups: []backup{{Name: "prod_backup", BackupType: fullLabel, Finished: 1697712000}}}
+	dev := &fakeSource{backups: []backup{{Name: "dev_backup", BackupType: differentialLabel, Finished: 1697712000}}}
+	targets := []Target{{Prefix: "prod", Source: prod}, {Prefix: "dev", Source: dev}}
+	GetMedusaInfo(context.Background(), targets, logger)
+	if got := countMetrics(medusaBackupStatusMetric); got != 2 {
+		t.Fatalf("\nVariables do not match:\ngot: %d\nwant: %d", got, 2)
+	}
+	// Failed prefix doesn't affect metrics for other prefixes.
+	dev.errs = []error{nil, errors.New("failed")}
+	GetMedusaInfo(context.Background(), targets, logger)
+	tests := []struct {
+		name       string
+		metric     prometheus.Gauge
+		wantStatus float64
+	}{
+		{"prod", medusaExporterStatusMetric.WithLabelValues(defaultClusterLabel, "prod", noneLabel), 1},
+		{"dev", medusaExporterStatusMetric.WithLabelValues(defaultClusterLabel, "dev", errorReason), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getGaugeValue(t, tt.metric); got != tt.wantStatus {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.wantStatus)
+			}
+		})
+	}
+	if got := countMetrics(medusaExporterStatusMetric); got != 2 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 2)
+	}
+	if got := countMetrics(medusaBackupStatusMetric); got != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 1)
+	}
+	if got := countMetrics(medusaBackupSinceLastCompletionSecondsMetric); got != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 1)
+	}
+}
+
+func TestGetMedusaInfoMultipleClusters(t *testing.T) {
+	resetStagingMetrics()
+	backups := []backup{{Name: "test_backup", BackupType: fullLabel, Started: 1697711900, Finished: 1697712000}}
+	targets := []Target{
+		{Cluster: "prod", Source: &fakeSource{backups: backups}},
+		{Cluster: "dev", Source: &fakeSource{backups: backups}},
+	}
+	GetMedusaInfo(context.Background(), targets, logger)
+	// The same backups and prefix in different clusters don't collide.
+	tests := []struct {
+		name   string
+		metric *prometheus.GaugeVec
+	}{
+		{"medusa_backup_status", medusaBackupStatusMetric},
+		{"medusa_backup_last_duration_seconds", medusaBackupLastDurationMetric},
+		{"medusa_exporter_status", medusaExporterStatusMetric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countMetrics(tt.metric); got != len(targets) {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, len(targets))
+			}
+		})
+	}
+	if got := getGaugeValue(t, medusaBackupLastDurationMetric.WithLabelValues(fullLabel, "dev", noPrefixLabel)); got != 100 {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, 100)
+	}
+}
+
+func getGaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+	m := &dto.Metric{}
+	if err := gauge.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetGauge().GetValue()
+}
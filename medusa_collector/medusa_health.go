@@ -0,0 +1,166 @@
+package medusa_collector
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	healthyPath = "/-/healthy"
+	readyPath   = "/-/ready"
+)
+
+// Reasons of not ready exporter.
+const (
+	noSuccessReason = "no successful collection yet"
+	staleReason     = "metrics snapshot is older than max age"
+)
+
+var (
+	// Guards collection state of targets and time of the last snapshot.
+	healthMutex sync.Mutex
+	// Collection state of targets by target name.
+	targetHealth = make(map[string]targetHealthState)
+	// Time when the last snapshot with data of at least one successful target was published.
+	lastSnapshotTime time.Time
+)
+
+// Collection state of one target.
+type targetHealthState struct {
+	lastAttempt         time.Time
+	lastSuccess         time.Time
+	consecutiveFailures int
+	lastError           string
+}
+
+// Target details in '/-/ready' response.
+type targetHealthStatus struct {
+	Target              string     `json:"target"`
+	Cluster             string     `json:"cluster"`
+	Prefix              string     `json:"prefix"`
+	LastAttempt         *time.Time `json:"last_attempt,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// Response of '/-/ready' endpoint.
+type readyStatus struct {
+	Ready              bool                 `json:"ready"`
+	Reason             string               `json:"reason,omitempty"`
+	LastSnapshot       *time.Time           `json:"last_snapshot,omitempty"`
+	SnapshotAgeSeconds float64              `json:"snapshot_age_seconds"`
+	MaxAgeSeconds      float64              `json:"max_age_seconds"`
+	Targets            []targetHealthStatus `json:"targets"`
+}
+
+// Save result of getting data from Medusa for target.
+func setTargetHealth(target Target, getDataErr error, t time.Time) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	state := targetHealth[target.Name()]
+	state.lastAttempt = t
+	if getDataErr != nil {
+		state.consecutiveFailures++
+		state.lastError = getDataErr.Error()
+	} else {
+		state.lastSuccess = t
+		state.consecutiveFailures = 0
+		state.lastError = ""
+	}
+	targetHealth[target.Name()] = state
+}
+
+// Delete collection state of removed target.
+func deleteTargetHealth(target Target) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	delete(targetHealth, target.Name())
+}
+
+// Save time of the published snapshot with data of at least one successful target.
+func setSnapshotTime(t time.Time) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	lastSnapshotTime = t
+}
+
+// Return readiness of exporter for targets.
+// Exporter is ready when at least one target was collected successfully
+// and the last snapshot is younger than max age, 0 max age disables the age check.
+// In scrape mode data is collected on scrape, so only target details are returned.
+func getReadyStatus(targets []Target, collection CollectionConfig, now time.Time) readyStatus {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	status := readyStatus{
+		Ready:         true,
+		MaxAgeSeconds: time.Duration(collection.ReadyMaxAge).Seconds(),
+		Targets:       make([]targetHealthStatus, 0, len(targets)),
+	}
+	hasSuccess := false
+	for _, target := range targets {
+		labels := targetLabels(target.Cluster, target.Prefix)
+		state := targetHealth[target.Name()]
+		targetStatus := targetHealthStatus{
+			Target:              target.Name(),
+			Cluster:             labels["cluster"],
+			Prefix:              labels["prefix"],
+			ConsecutiveFailures: state.consecutiveFailures,
+			LastError:           state.lastError,
+		}
+		if !state.lastAttempt.IsZero() {
+			targetStatus.LastAttempt = &state.lastAttempt
+		}
+		if !state.lastSuccess.IsZero() {
+			targetStatus.LastSuccess = &state.lastSuccess
+			hasSuccess = true
+		}
+		status.Targets = append(status.Targets, targetStatus)
+	}
+	if !lastSnapshotTime.IsZero() {
+		snapshotTime := lastSnapshotTime
+		status.LastSnapshot = &snapshotTime
+		status.SnapshotAgeSeconds = now.Sub(snapshotTime).Seconds()
+	}
+	if collection.Mode == ScrapeMode {
+		return status
+	}
+	switch {
+	case !hasSuccess:
+		status.Ready = false
+		status.Reason = noSuccessReason
+	case collection.ReadyMaxAge > 0 && now.Sub(lastSnapshotTime) > time.Duration(collection.ReadyMaxAge):
+		status.Ready = false
+		status.Reason = staleReason
+	}
+	return status
+}
+
+// Handler reports that exporter process is alive.
+func healthyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("Medusa exporter is Healthy.\n"))
+	})
+}
+
+// Handler reports readiness of exporter with details for current targets of manager.
+// Status 503 is returned when exporter isn't ready.
+func readyHandler(manager *ConfigManager, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := getReadyStatus(manager.Targets(), manager.Collection(), time.Now())
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			logger.Error("Write ready status failed", "err", err)
+		}
+	})
+}
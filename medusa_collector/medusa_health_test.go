@@ -0,0 +1,163 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// Reset collection state of targets and time of the last snapshot.
+func resetHealth() {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	targetHealth = make(map[string]targetHealthState)
+	lastSnapshotTime = time.Time{}
+}
+
+func TestGetReadyStatus(t *testing.T) {
+	defer resetHealth()
+	now := time.Date(2023, 10, 19, 12, 0, 0, 0, time.UTC)
+	prod := Target{Cluster: "prod", Prefix: "cluster1"}
+	dev := Target{Cluster: "dev"}
+	type results struct {
+		prodErr      error
+		devErr       error
+		snapshotTime time.Time
+	}
+	tests := []struct {
+		name       string
+		collection CollectionConfig
+		results    *results
+		wantReady  bool
+		wantReason string
+	}{
+		{"NoCollection", CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)}, nil, false, noSuccessReason},
+		{
+			"AllFailed",
+			CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)},
+			&results{errors.New("failed"), errors.New("failed"), now.Add(-time.Minute)},
+			false,
+			noSuccessReason,
+		},
+		{
+			"OneSucceeded",
+			CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)},
+			&results{nil, errors.New("failed"), now.Add(-time.Minute)},
+			true,
+			"",
+		},
+		{
+			"StaleSnapshot",
+			CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)},
+			&results{nil, nil, now.Add(-2 * time.Hour)},
+			false,
+			staleReason,
+		},
+		{
+			"MaxAgeDisabled",
+			CollectionConfig{Mode: IntervalMode},
+			&results{nil, nil, now.Add(-2 * time.Hour)},
+			true,
+			"",
+		},
+		{"ScrapeMode", CollectionConfig{Mode: ScrapeMode, ReadyMaxAge: model.Duration(time.Hour)}, nil, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHealth()
+			if tt.results != nil {
+				setTargetHealth(prod, tt.results.prodErr, tt.results.snapshotTime)
+				setTargetHealth(dev, tt.results.devErr, tt.results.snapshotTime)
+				setSnapshotTime(tt.results.snapshotTime)
+			}
+			got := getReadyStatus([]Target{prod, dev}, tt.collection, now)
+			if got.Ready != tt.wantReady || got.Reason != tt.wantReason {
+				t.Errorf("\nVariables do not match:\ngot: ready=%v, reason=%q\nwant: ready=%v, reason=%q", got.Ready, got.Reason, tt.wantReady, tt.wantReason)
+			}
+			if len(got.Targets) != 2 || got.Targets[0].Target != "prod/cluster1" || got.Targets[1].Prefix != noPrefixLabel {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: details for prod/cluster1 and dev/no-prefix", got.Targets)
+			}
+		})
+	}
+}
+
+func TestReadyHandler(t *testing.T) {
+	defer resetHealth()
+	resetHealth()
+	target := Target{Cluster: "prod", Source: &fakeSource{}}
+	manager := newTestConfigManager([]Target{target}, CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)})
+	server := httptest.NewServer(readyHandler(manager, logger))
+	defer server.Close()
+	tests := []struct {
+		name        string
+		getDataErr  error
+		wantCode    int
+		wantFailure int
+		wantError   string
+	}{
+		{"NotReady", errors.New("failed"), http.StatusServiceUnavailable, 1, "failed"},
+		{"Ready", nil, http.StatusOK, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTargetHealth(target, tt.getDataErr, time.Now())
+			setSnapshotTime(time.Now())
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", resp.StatusCode, tt.wantCode)
+			}
+			var status readyStatus
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				t.Fatal(err)
+			}
+			if len(status.Targets) != 1 {
+				t.Fatalf("\nVariables do not match:\ngot: %d targets\nwant: 1 target", len(status.Targets))
+			}
+			got := status.Targets[0]
+			if got.Target != "prod/no-prefix" || got.ConsecutiveFailures != tt.wantFailure || got.LastError != tt.wantError || got.LastAttempt == nil {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: failures=%d, error=%q", got, tt.wantFailure, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestGetMedusaInfoHealth(t *testing.T) {
+	defer resetHealth()
+	resetHealth()
+	target := Target{Cluster: "health", Source: &fakeSource{backups: testIndexBackups()}}
+	before := time.Now()
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	status := getReadyStatus([]Target{target}, CollectionConfig{Mode: IntervalMode, ReadyMaxAge: model.Duration(time.Hour)}, time.Now())
+	if !status.Ready || status.LastSnapshot == nil || status.LastSnapshot.Before(before) {
+		t.Errorf("\nVariables do not match:\ngot: %+v\nwant: ready with the last snapshot", status)
+	}
+}
+
+func TestGetMedusaInfoHealthFailed(t *testing.T) {
+	defer resetHealth()
+	resetHealth()
+	target := Target{Cluster: "health", Source: &fakeSource{backups: testIndexBackups()}}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	status := getReadyStatus([]Target{target}, CollectionConfig{Mode: IntervalMode}, time.Now())
+	if status.LastSnapshot == nil {
+		t.Fatalf("\nVariables do not match:\ngot: %+v\nwant: the last snapshot", status)
+	}
+	snapshotTime := *status.LastSnapshot
+	// Failed collection doesn't advance time of the last snapshot.
+	target.Source = &fakeSource{errs: []error{errors.New("failed"), errors.New("failed"), errors.New("failed")}}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	status = getReadyStatus([]Target{target}, CollectionConfig{Mode: IntervalMode}, time.Now())
+	if status.LastSnapshot == nil || !status.LastSnapshot.Equal(snapshotTime) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", status.LastSnapshot, snapshotTime)
+	}
+}
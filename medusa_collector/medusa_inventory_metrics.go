@@ -0,0 +1,190 @@
+package medusa_collector
+
+import (
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	medusaBackupInventoryBackupsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_backups",
+		Help: "Number of full or differential backups.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryCompleteMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_complete_backups",
+		Help: "Number of complete full or differential backups.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryIncompleteMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_incomplete_backups",
+		Help: "Number of incomplete full or differential backups.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryOldestMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_oldest_start_timestamp_seconds",
+		Help: "Start time of the oldest full or differential backup.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryNewestMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_newest_start_timestamp_seconds",
+		Help: "Start time of the newest full or differential backup.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryReferencedSizeMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_referenced_size_bytes",
+		Help: "Sum of sizes of full or differential backups, files shared between backups are counted in each backup.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryReferencedObjectsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_referenced_objects",
+		Help: "Sum of numbers of objects in full or differential backups, files shared between backups are counted in each backup.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryStoredSizeMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_stored_size_bytes",
+		Help: "Size of unique files of full or differential backups, files shared between backups are counted once.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupInventoryStoredObjectsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_inventory_stored_objects",
+		Help: "Number of unique files of full or differential backups, files shared between backups are counted once.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+)
+
+// Summary of backups of one type.
+type backupInventory struct {
+	backups  int
+	complete int
+	oldest   int64
+	newest   int64
+	// Sums of backup sizes and objects, including files referenced from earlier backups.
+	referencedSize       int64
+	referencedNumObjects int64
+	// Sizes of unique files by path, nil if objects of any backup are unknown.
+	storedObjects map[string]int64
+}
+
+// Set backup metrics for each backup type:
+//   - medusa_backup_inventory_backups
+//   - medusa_backup_inventory_complete_backups
+//   - medusa_backup_inventory_incomplete_backups
+//   - medusa_backup_inventory_oldest_start_timestamp_seconds
+//   - medusa_backup_inventory_newest_start_timestamp_seconds
+//   - medusa_backup_inventory_referenced_size_bytes
+//   - medusa_backup_inventory_referenced_objects
+//   - medusa_backup_inventory_stored_size_bytes
+//   - medusa_backup_inventory_stored_objects
+//
+// Size and objects of backup include files, which are reused from earlier backups,
+// so their sums are larger than data actually stored.
+// Stored size and objects are calculated from unique paths of manifest objects,
+// so they are known only for storage source.
+// Counters are set for each backup type, even if there are no backups of the type,
+// timestamps and stored values are set only if there are backups of the type.
+func getBackupInventoryMetrics(backups []backup, backupTypes []string, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
+	inventories := make(map[string]*backupInventory, len(backupTypes))
+	for _, backupType := range backupTypes {
+		inventories[backupType] = &backupInventory{storedObjects: make(map[string]int64)}
+	}
+	for _, singleBackup := range backups {
+		inventory, ok := inventories[singleBackup.BackupType]
+		if !ok {
+			continue
+		}
+		inventory.backups++
+		if singleBackup.Finished > 0 {
+			inventory.complete++
+		}
+		if inventory.oldest == 0 || singleBackup.Started < inventory.oldest {
+			inventory.oldest = singleBackup.Started
+		}
+		if singleBackup.Started > inventory.newest {
+			inventory.newest = singleBackup.Started
+		}
+		inventory.referencedSize += singleBackup.Size
+		inventory.referencedNumObjects += singleBackup.NumObjects
+		if singleBackup.objects == nil {
+			inventory.storedObjects = nil
+		}
+		if inventory.storedObjects != nil {
+			for _, object := range singleBackup.objects {
+				inventory.storedObjects[object.key] = object.size
+			}
+		}
+	}
+	for _, backupType := range backupTypes {
+		inventory := inventories[backupType]
+		type inventoryMetric struct {
+			metric *prometheus.GaugeVec
+			name   string
+			value  float64
+		}
+		metrics := []inventoryMetric{
+			{medusaBackupInventoryBackupsMetric, "medusa_backup_inventory_backups", float64(inventory.backups)},
+			{medusaBackupInventoryCompleteMetric, "medusa_backup_inventory_complete_backups", float64(inventory.complete)},
+			{medusaBackupInventoryIncompleteMetric, "medusa_backup_inventory_incomplete_backups", float64(inventory.backups - inventory.complete)},
+			{medusaBackupInventoryReferencedSizeMetric, "medusa_backup_inventory_referenced_size_bytes", float64(inventory.referencedSize)},
+			{medusaBackupInventoryReferencedObjectsMetric, "medusa_backup_inventory_referenced_objects", float64(inventory.referencedNumObjects)},
+		}
+		if inventory.backups > 0 {
+			metrics = append(metrics,
+				inventoryMetric{medusaBackupInventoryOldestMetric, "medusa_backup_inventory_oldest_start_timestamp_seconds", float64(inventory.oldest)},
+				inventoryMetric{medusaBackupInventoryNewestMetric, "medusa_backup_inventory_newest_start_timestamp_seconds", float64(inventory.newest)},
+			)
+		}
+		if inventory.backups > 0 && inventory.storedObjects != nil {
+			var storedSize int64
+			for _, size := range inventory.storedObjects {
+				storedSize += size
+			}
+			metrics = append(metrics,
+				inventoryMetric{medusaBackupInventoryStoredSizeMetric, "medusa_backup_inventory_stored_size_bytes", float64(storedSize)},
+				inventoryMetric{medusaBackupInventoryStoredObjectsMetric, "medusa_backup_inventory_stored_objects", float64(len(inventory.storedObjects))},
+			)
+		}
+		for _, metric := range metrics {
+			setUpMetric(
+				metric.metric,
+				metric.name,
+				metric.value,
+				setUpMetricValueFun,
+				logger,
+				backupType,
+				cluster,
+				prefix,
+			)
+		}
+	}
+}
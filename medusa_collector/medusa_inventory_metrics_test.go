@@ -0,0 +1,153 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func resetBackupInventoryMetrics() {
+	medusaBackupInventoryBackupsMetric.Reset()
+	medusaBackupInventoryCompleteMetric.Reset()
+	medusaBackupInventoryIncompleteMetric.Reset()
+	medusaBackupInventoryOldestMetric.Reset()
+	medusaBackupInventoryNewestMetric.Reset()
+	medusaBackupInventoryReferencedSizeMetric.Reset()
+	medusaBackupInventoryReferencedObjectsMetric.Reset()
+	medusaBackupInventoryStoredSizeMetric.Reset()
+	medusaBackupInventoryStoredObjectsMetric.Reset()
+}
+
+func TestGetBackupInventoryMetrics(t *testing.T) {
+	// Objects of full_2 are unknown, so stored values aren't set for full backups.
+	backups := append(testIndexBackups(), backup{
+		Name:       "full_2",
+		BackupType: fullLabel,
+		Started:    1697811900,
+		Finished:   1697812000,
+		NumObjects: 6,
+		Size:       300,
+	}, backup{
+		Name:       "diff_2",
+		BackupType: differentialLabel,
+		Started:    1697822000,
+		Finished:   1697822100,
+		NumObjects: 2,
+		Size:       74,
+		// One file is reused from diff_1.
+		objects: []storageObject{
+			{key: "node1/data/ks/tbl/b", size: 24},
+			{key: "node1/data/ks/tbl/c", size: 50},
+		},
+	})
+	tests := []struct {
+		name        string
+		backups     []backup
+		backupTypes []string
+		testText    string
+	}{
+		{
+			"AllBackupTypes",
+			backups,
+			[]string{differentialLabel, fullLabel},
+			`# HELP medusa_backup_inventory_backups Number of full or differential backups.
+# TYPE medusa_backup_inventory_backups gauge
+medusa_backup_inventory_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 2
+medusa_backup_inventory_backups{backup_type="full",cluster="default",prefix="no-prefix"} 2
+# HELP medusa_backup_inventory_complete_backups Number of complete full or differential backups.
+# TYPE medusa_backup_inventory_complete_backups gauge
+medusa_backup_inventory_complete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 1
+medusa_backup_inventory_complete_backups{backup_type="full",cluster="default",prefix="no-prefix"} 2
+# HELP medusa_backup_inventory_incomplete_backups Number of incomplete full or differential backups.
+# TYPE medusa_backup_inventory_incomplete_backups gauge
+medusa_backup_inventory_incomplete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 1
+medusa_backup_inventory_incomplete_backups{backup_type="full",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_inventory_newest_start_timestamp_seconds Start time of the newest full or differential backup.
+# TYPE medusa_backup_inventory_newest_start_timestamp_seconds gauge
+medusa_backup_inventory_newest_start_timestamp_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 1.697822e+09
+medusa_backup_inventory_newest_start_timestamp_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 1.6978119e+09
+# HELP medusa_backup_inventory_oldest_start_timestamp_seconds Start time of the oldest full or differential backup.
+# TYPE medusa_backup_inventory_oldest_start_timestamp_seconds gauge
+medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 1.697722e+09
+medusa_backup_inventory_oldest_start_timestamp_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 1.6977119e+09
+# HELP medusa_backup_inventory_referenced_objects Sum of numbers of objects in full or differential backups, files shared between backups are counted in each backup.
+# TYPE medusa_backup_inventory_referenced_objects gauge
+medusa_backup_inventory_referenced_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 4
+medusa_backup_inventory_referenced_objects{backup_type="full",cluster="default",prefix="no-prefix"} 10
+# HELP medusa_backup_inventory_referenced_size_bytes Sum of sizes of full or differential backups, files shared between backups are counted in each backup.
+# TYPE medusa_backup_inventory_referenced_size_bytes gauge
+medusa_backup_inventory_referenced_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 198
+medusa_backup_inventory_referenced_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"} 548
+# HELP medusa_backup_inventory_stored_objects Number of unique files of full or differential backups, files shared between backups are counted once.
+# TYPE medusa_backup_inventory_stored_objects gauge
+medusa_backup_inventory_stored_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 3
+# HELP medusa_backup_inventory_stored_size_bytes Size of unique files of full or differential backups, files shared between backups are counted once.
+# TYPE medusa_backup_inventory_stored_size_bytes gauge
+medusa_backup_inventory_stored_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 174
+`,
+		},
+		{
+			"NoBackupsOfType",
+			backups[:1],
+			[]string{differentialLabel},
+			`# HELP medusa_backup_inventory_backups Number of full or differential backups.
+# TYPE medusa_backup_inventory_backups gauge
+medusa_backup_inventory_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_inventory_complete_backups Number of complete full or differential backups.
+# TYPE medusa_backup_inventory_complete_backups gauge
+medusa_backup_inventory_complete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_inventory_incomplete_backups Number of incomplete full or differential backups.
+# TYPE medusa_backup_inventory_incomplete_backups gauge
+medusa_backup_inventory_incomplete_backups{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_inventory_referenced_objects Sum of numbers of objects in full or differential backups, files shared between backups are counted in each backup.
+# TYPE medusa_backup_inventory_referenced_objects gauge
+medusa_backup_inventory_referenced_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_inventory_referenced_size_bytes Sum of sizes of full or differential backups, files shared between backups are counted in each backup.
+# TYPE medusa_backup_inventory_referenced_size_bytes gauge
+medusa_backup_inventory_referenced_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetBackupInventoryMetrics()
+			getBackupInventoryMetrics(tt.backups, tt.backupTypes, "", "", setUpMetricValue, logger)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(
+				medusaBackupInventoryBackupsMetric,
+				medusaBackupInventoryCompleteMetric,
+				medusaBackupInventoryIncompleteMetric,
+				medusaBackupInventoryOldestMetric,
+				medusaBackupInventoryNewestMetric,
+				medusaBackupInventoryReferencedSizeMetric,
+				medusaBackupInventoryReferencedObjectsMetric,
+				medusaBackupInventoryStoredSizeMetric,
+				medusaBackupInventoryStoredObjectsMetric,
+			)
+			if got := gatherText(t, reg); got != tt.testText {
+				t.Errorf("\nVariables do not match, metrics:\n%s\nwant:\n%s", got, tt.testText)
+			}
+		})
+	}
+}
+
+func TestGetMedusaInfoInventory(t *testing.T) {
+	resetStagingMetrics()
+	targets := []Target{
+		{Cluster: "inventory", Prefix: "ok", Source: &fakeSource{backups: testIndexBackups()}},
+		{Cluster: "inventory", Prefix: "failed", Source: &fakeSource{errs: []error{errors.New("failed"), errors.New("failed")}}},
+	}
+	GetMedusaInfo(context.Background(), targets, logger)
+	text := gatherText(t, snapshotGatherer())
+	want := `medusa_backup_inventory_backups{backup_type="full",cluster="inventory",prefix="ok"} 1`
+	if !strings.Contains(text, want) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", text, want)
+	}
+	// Failed target has no backup data.
+	if strings.Contains(text, `medusa_backup_inventory_backups{backup_type="full",cluster="inventory",prefix="failed"}`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no inventory metrics for failed target", text)
+	}
+}
@@ -5,34 +5,41 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
-	medusaBackupSinceLastCompletionSecondsMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	medusaBackupSinceLastCompletionSecondsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_since_last_completion_seconds",
 		Help: "Time since last full or differential backup completion.",
 	},
 		[]string{
-			"backup_type"})
-	medusaBackupLastDurationMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupLastDurationMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_last_duration_seconds",
 		Help: "Backup duration for the last full or differential backup.",
 	},
 		[]string{
-			"backup_type"})
-	medusaBackupLastDatabaseSizeMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupLastDatabaseSizeMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_last_size_bytes",
 		Help: "Backup size for the last full or differential backup.",
 	},
 		[]string{
-			"backup_type"})
-	medusaBackupLastObjectsMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupLastObjectsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "medusa_backup_last_objects",
 		Help: "Number of objects in backup for the last full or differential backup.",
 	},
 		[]string{
-			"backup_type"})
+			"backup_type",
+			"cluster",
+			"prefix"})
 )
 
 // Set backup metrics:
@@ -40,18 +47,20 @@ var (
 //   - medusa_backup_last_duration_seconds
 //   - medusa_backup_last_size_bytes
 //   - medusa_backup_last_objects
-func getBackupLastMetrics(lastBackups lastBackupsStruct, currentUnixTime int64, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+func getBackupLastMetrics(lastBackups lastBackupsStruct, currentUnixTime int64, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
 	// Differential backup metrics
 	if lastBackups.differential.finished > 0 {
-		setBackupLastMetrics(lastBackups.differential, currentUnixTime, setUpMetricValueFun, logger)
+		setBackupLastMetrics(lastBackups.differential, currentUnixTime, cluster, prefix, setUpMetricValueFun, logger)
 	}
 	// Full backup metrics
 	if lastBackups.full.finished > 0 {
-		setBackupLastMetrics(lastBackups.full, currentUnixTime, setUpMetricValueFun, logger)
+		setBackupLastMetrics(lastBackups.full, currentUnixTime, cluster, prefix, setUpMetricValueFun, logger)
 	}
 }
 
-func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
 	// Seconds since the last completed backups.
 	setUpMetric(
 		medusaBackupSinceLastCompletionSecondsMetric,
@@ -60,6 +69,8 @@ func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, setUpMetri
 		setUpMetricValueFun,
 		logger,
 		backup.backupType,
+		cluster,
+		prefix,
 	)
 	// Last backup duration.
 	backupDuration := float64(backup.finished - backup.started)
@@ -70,6 +81,8 @@ func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, setUpMetri
 		setUpMetricValueFun,
 		logger,
 		backup.backupType,
+		cluster,
+		prefix,
 	)
 	// Last backup size.
 	setUpMetric(
@@ -79,6 +92,8 @@ func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, setUpMetri
 		setUpMetricValueFun,
 		logger,
 		backup.backupType,
+		cluster,
+		prefix,
 	)
 	// Last backup objects.
 	setUpMetric(
@@ -88,12 +103,7 @@ func setBackupLastMetrics(backup backupStruct, currentUnixTime int64, setUpMetri
 		setUpMetricValueFun,
 		logger,
 		backup.backupType,
+		cluster,
+		prefix,
 	)
 }
-
-func resetBackupLastMetrics() {
-	medusaBackupSinceLastCompletionSecondsMetric.Reset()
-	medusaBackupLastDurationMetric.Reset()
-	medusaBackupLastDatabaseSizeMetric.Reset()
-	medusaBackupLastObjectsMetric.Reset()
-}
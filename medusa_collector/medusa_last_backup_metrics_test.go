@@ -11,6 +11,13 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+func resetBackupLastMetrics() {
+	medusaBackupSinceLastCompletionSecondsMetric.Reset()
+	medusaBackupLastDurationMetric.Reset()
+	medusaBackupLastDatabaseSizeMetric.Reset()
+	medusaBackupLastObjectsMetric.Reset()
+}
+
 func TestGetBackupLastMetrics(t *testing.T) {
 	type args struct {
 		lastBackups         lastBackupsStruct
@@ -45,20 +52,20 @@ func TestGetBackupLastMetrics(t *testing.T) {
 				setUpMetricValue,
 				`# HELP medusa_backup_last_duration_seconds Backup duration for the last full or differential backup.
 # TYPE medusa_backup_last_duration_seconds gauge
-medusa_backup_last_duration_seconds{backup_type="differential"} 100
-medusa_backup_last_duration_seconds{backup_type="full"} 100
+medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 100
+medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_last_objects Number of objects in backup for the last full or differential backup.
 # TYPE medusa_backup_last_objects gauge
-medusa_backup_last_objects{backup_type="differential"} 100
-medusa_backup_last_objects{backup_type="full"} 100
+medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 100
+medusa_backup_last_objects{backup_type="full",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_last_size_bytes Backup size for the last full or differential backup.
 # TYPE medusa_backup_last_size_bytes gauge
-medusa_backup_last_size_bytes{backup_type="differential"} 1024
-medusa_backup_last_size_bytes{backup_type="full"} 1024
+medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 1024
+medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"} 1024
 # HELP medusa_backup_since_last_completion_seconds Time since last full or differential backup completion.
 # TYPE medusa_backup_since_last_completion_seconds gauge
-medusa_backup_since_last_completion_seconds{backup_type="differential"} 10000
-medusa_backup_since_last_completion_seconds{backup_type="full"} 10000
+medusa_backup_since_last_completion_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 10000
+medusa_backup_since_last_completion_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 10000
 `,
 			},
 		},
@@ -81,16 +88,16 @@ medusa_backup_since_last_completion_seconds{backup_type="full"} 10000
 				setUpMetricValue,
 				`# HELP medusa_backup_last_duration_seconds Backup duration for the last full or differential backup.
 # TYPE medusa_backup_last_duration_seconds gauge
-medusa_backup_last_duration_seconds{backup_type="full"} 100
+medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_last_objects Number of objects in backup for the last full or differential backup.
 # TYPE medusa_backup_last_objects gauge
-medusa_backup_last_objects{backup_type="full"} 200
+medusa_backup_last_objects{backup_type="full",cluster="default",prefix="no-prefix"} 200
 # HELP medusa_backup_last_size_bytes Backup size for the last full or differential backup.
 # TYPE medusa_backup_last_size_bytes gauge
-medusa_backup_last_size_bytes{backup_type="full"} 2048
+medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"} 2048
 # HELP medusa_backup_since_last_completion_seconds Time since last full or differential backup completion.
 # TYPE medusa_backup_since_last_completion_seconds gauge
-medusa_backup_since_last_completion_seconds{backup_type="full"} 20000
+medusa_backup_since_last_completion_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 20000
 `,
 			},
 		},
@@ -113,16 +120,16 @@ medusa_backup_since_last_completion_seconds{backup_type="full"} 20000
 				setUpMetricValue,
 				`# HELP medusa_backup_last_duration_seconds Backup duration for the last full or differential backup.
 # TYPE medusa_backup_last_duration_seconds gauge
-medusa_backup_last_duration_seconds{backup_type="differential"} 100
+medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_last_objects Number of objects in backup for the last full or differential backup.
 # TYPE medusa_backup_last_objects gauge
-medusa_backup_last_objects{backup_type="differential"} 50
+medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 50
 # HELP medusa_backup_last_size_bytes Backup size for the last full or differential backup.
 # TYPE medusa_backup_last_size_bytes gauge
-medusa_backup_last_size_bytes{backup_type="differential"} 512
+medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 512
 # HELP medusa_backup_since_last_completion_seconds Time since last full or differential backup completion.
 # TYPE medusa_backup_since_last_completion_seconds gauge
-medusa_backup_since_last_completion_seconds{backup_type="differential"} 10000
+medusa_backup_since_last_completion_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 10000
 `,
 			},
 		},
@@ -149,20 +156,20 @@ medusa_backup_since_last_completion_seconds{backup_type="differential"} 10000
 				setUpMetricValue,
 				`# HELP medusa_backup_last_duration_seconds Backup duration for the last full or differential backup.
 # TYPE medusa_backup_last_duration_seconds gauge
-medusa_backup_last_duration_seconds{backup_type="differential"} 100
-medusa_backup_last_duration_seconds{backup_type="full"} 100
+medusa_backup_last_duration_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 100
+medusa_backup_last_duration_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 100
 # HELP medusa_backup_last_objects Number of objects in backup for the last full or differential backup.
 # TYPE medusa_backup_last_objects gauge
-medusa_backup_last_objects{backup_type="differential"} 50
-medusa_backup_last_objects{backup_type="full"} 200
+medusa_backup_last_objects{backup_type="differential",cluster="default",prefix="no-prefix"} 50
+medusa_backup_last_objects{backup_type="full",cluster="default",prefix="no-prefix"} 200
 # HELP medusa_backup_last_size_bytes Backup size for the last full or differential backup.
 # TYPE medusa_backup_last_size_bytes gauge
-medusa_backup_last_size_bytes{backup_type="differential"} 512
-medusa_backup_last_size_bytes{backup_type="full"} 2048
+medusa_backup_last_size_bytes{backup_type="differential",cluster="default",prefix="no-prefix"} 512
+medusa_backup_last_size_bytes{backup_type="full",cluster="default",prefix="no-prefix"} 2048
 # HELP medusa_backup_since_last_completion_seconds Time since last full or differential backup completion.
 # TYPE medusa_backup_since_last_completion_seconds gauge
-medusa_backup_since_last_completion_seconds{backup_type="differential"} 10000
-medusa_backup_since_last_completion_seconds{backup_type="full"} 20000
+medusa_backup_since_last_completion_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 10000
+medusa_backup_since_last_completion_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 20000
 `,
 			},
 		},
@@ -172,7 +179,7 @@ medusa_backup_since_last_completion_seconds{backup_type="full"} 20000
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
 			resetBackupLastMetrics()
-			getBackupLastMetrics(tt.args.lastBackups, tt.args.currentUnixTime, tt.args.setUpMetricValueFun, lc)
+			getBackupLastMetrics(tt.args.lastBackups, tt.args.currentUnixTime, "", "", tt.args.setUpMetricValueFun, lc)
 			reg := prometheus.NewRegistry()
 			reg.MustRegister(
 				medusaBackupSinceLastCompletionSecondsMetric,
@@ -243,7 +250,7 @@ func TestGetBackupLastMetricsErrorsAndDebugs(t *testing.T) {
 			resetBackupLastMetrics()
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			getBackupLastMetrics(tt.args.lastBackups, tt.args.currentUnixTime, tt.args.setUpMetricValueFun, lc)
+			getBackupLastMetrics(tt.args.lastBackups, tt.args.currentUnixTime, "", "", tt.args.setUpMetricValueFun, lc)
 			errorsOutputCount := strings.Count(out.String(), "level=ERROR")
 			debugsOutputCount := strings.Count(out.String(), "level=DEBUG")
 			if tt.args.errorsCount != errorsOutputCount || tt.args.debugsCount != debugsOutputCount {
@@ -2,26 +2,31 @@ package medusa_collector
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 type setUpMetricValueFunType func(metric *prometheus.GaugeVec, value float64, labels ...string) error
 
-var execCommand = exec.Command
-
 const (
 	// https://golang.org/pkg/time/#Time.Format
-	layout            = "2006-01-02 15:04:05"
-	noneLabel         = "none"
-	noPrefixLabel     = "no-prefix"
-	fullLabel         = "full"
-	differentialLabel = "differential"
+	layout        = "2006-01-02 15:04:05"
+	noneLabel     = "none"
+	noPrefixLabel = "no-prefix"
+	// Cluster label for target without name.
+	defaultClusterLabel = "default"
+	fullLabel           = "full"
+	differentialLabel   = "differential"
+	// Time to wait for command output after the process group is killed.
+	commandWaitDelay = 5 * time.Second
 )
 
 type backupStruct struct {
@@ -77,7 +82,7 @@ func concatExecArgs(slices [][]string) []string {
 	return tmp
 }
 
-func getInfoData(config, prefix string, logger *slog.Logger) ([]byte, error) {
+func getInfoData(ctx context.Context, config, prefix string, execCommand execCommandFunType, logger *slog.Logger) ([]byte, error) {
 	app := "medusa"
 	// Don't change the order of arguments.
 	// See medusa help:
@@ -89,7 +94,10 @@ func getInfoData(config, prefix string, logger *slog.Logger) ([]byte, error) {
 	}
 	// Finally arguments for exec command.
 	concatArgs := concatExecArgs(args)
-	cmd := execCommand(app, concatArgs...)
+	cmd := execCommand(ctx, app, concatArgs...)
+	// When context is done, kill medusa and processes spawned by it.
+	setKillProcessGroup(cmd)
+	cmd.WaitDelay = commandWaitDelay
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -100,6 +108,10 @@ func getInfoData(config, prefix string, logger *slog.Logger) ([]byte, error) {
 			"Medusa message",
 			"msg", stderr.String(),
 		)
+		// Process was killed because of timeout or cancellation.
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("medusa command terminated: %w", ctx.Err())
+		}
 		return nil, err
 	}
 	// If stderr from medusa is not empty,
@@ -114,6 +126,19 @@ func getInfoData(config, prefix string, logger *slog.Logger) ([]byte, error) {
 	return stdout.Bytes(), err
 }
 
+// Return exit code of medusa command.
+// If command wasn't started or was killed, -1 is returned.
+func returnExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
 func parseResult(output []byte) ([]backup, error) {
 	var backups []backup
 	err := json.Unmarshal(output, &backups)
@@ -152,12 +177,6 @@ func setUpMetric(metric *prometheus.GaugeVec, metricName string, value float64,
 	}
 }
 
-func resetMetrics() {
-	resetBackupMetrics()
-	resetBackupLastMetrics()
-	resetExporterMetrics()
-}
-
 func initLastBackupStruct() lastBackupsStruct {
 	return lastBackupsStruct{
 		full:         backupStruct{backupType: fullLabel},
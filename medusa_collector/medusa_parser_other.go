@@ -0,0 +1,15 @@
+//go:build !unix
+
+package medusa_collector
+
+import (
+	"os/exec"
+)
+
+// Process groups aren't supported, so only command process is killed
+// when context is done.
+func setKillProcessGroup(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}
@@ -2,11 +2,15 @@ package medusa_collector
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os/exec"
 	"reflect"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -258,11 +262,9 @@ func TestGetInfoData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockData = tt.mockTestData
-			execCommand = fakeExecCommand
-			defer func() { execCommand = exec.Command }()
 			out := &bytes.Buffer{}
 			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
-			got, err := getInfoData(tt.config, tt.prefix, lc)
+			got, err := getInfoData(context.Background(), tt.config, tt.prefix, fakeExecCommand, lc)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
 				return
@@ -274,6 +276,40 @@ func TestGetInfoData(t *testing.T) {
 	}
 }
 
+func TestGetInfoDataTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := getInfoData(ctx, "", "", fakeHangExecCommand, logger)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("\nVariables do not match:\ngot error: %v\nwant error: %v", err, context.DeadlineExceeded)
+	}
+	// Process group is killed, so there is no wait for child process holding stdout.
+	if elapsed := time.Since(start); elapsed >= commandWaitDelay {
+		t.Errorf("\nCommand wasn't killed in time: %v", elapsed)
+	}
+}
+
+func TestReturnExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"NoError", nil, 0},
+		{"ExitError", exec.Command("sh", "-c", "exit 3").Run(), 3},
+		{"NotStarted", exec.Command("medusa_not_exist").Run(), -1},
+		{"Terminated", fmt.Errorf("medusa command terminated: %w", context.DeadlineExceeded), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := returnExitCode(tt.err); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSetUpMetricValue(t *testing.T) {
 	type args struct {
 		metric *prometheus.GaugeVec
@@ -0,0 +1,17 @@
+//go:build unix
+
+package medusa_collector
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// Run command in its own process group, so when context is done
+// the whole group is killed, including processes spawned by command.
+func setKillProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,106 @@
+package medusa_collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const probePath = "/probe"
+
+// Handler gets data from Medusa for one target on each request
+// and returns metrics for this target only from fresh registry.
+// Targets and timeout are taken from current configuration of manager.
+func probeHandler(manager *ConfigManager, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("target")
+		if name == "" {
+			http.Error(w, "Target parameter is missing", http.StatusBadRequest)
+			return
+		}
+		target, ok := findTarget(manager.Targets(), name)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown target %q", name), http.StatusNotFound)
+			return
+		}
+		// Probe is canceled when Prometheus stops waiting for response.
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		if timeout := time.Duration(manager.Collection().Timeout); timeout > 0 {
+			ctx, cancel = context.WithTimeout(r.Context(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(r.Context())
+		}
+		defer cancel()
+		registry := prometheus.NewRegistry()
+		prometheus.WrapRegistererWith(target.Labels, registry).MustRegister(probeTarget(ctx, target, logger.With("target", name)))
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
+
+// Return target with name.
+func findTarget(targets []Target, name string) (Target, bool) {
+	for _, target := range targets {
+		if target.Name() == name {
+			return target, true
+		}
+	}
+	return Target{}, false
+}
+
+// Get data from Medusa for target and return collector with its metrics.
+// Exporter gauges of getting data are set in the collector too,
+// global metrics are not changed, except exporter counters of attempts, outcomes and errors.
+func probeTarget(ctx context.Context, target Target, logger *slog.Logger) *probeCollector {
+	currentUnixTime := time.Now().Unix()
+	collector := &probeCollector{index: make(map[string]int)}
+	backups, err := fetchBackups(ctx, target.Source, target.Cluster, target.Prefix, collector.setUpMetricValue, logger)
+	if err != nil {
+		logger.Error("Get data from Medusa failed", "source", target.Source.Name(), "err", err)
+	}
+	getTargetMetrics(target, targetResult{backups: backups, err: err}, currentUnixTime, collector.setUpMetricValue, logger)
+	return collector
+}
+
+// Collector which exposes metrics of one probe.
+type probeCollector struct {
+	metrics []prometheus.Metric
+	// Index of metric by its desc and labels, to replace metric with the same labels.
+	index map[string]int
+}
+
+// Describe sends nothing, so the collector is unchecked.
+func (c *probeCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+func (c *probeCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// Save metric with labels as const metric instead of setting global metric vector.
+func (c *probeCollector) setUpMetricValue(metric *prometheus.GaugeVec, value float64, labels ...string) error {
+	descs := make(chan *prometheus.Desc, 1)
+	metric.Describe(descs)
+	desc := <-descs
+	constMetric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	if err != nil {
+		return err
+	}
+	key := desc.String() + "\xff" + strings.Join(labels, "\xff")
+	if i, ok := c.index[key]; ok {
+		c.metrics[i] = constMetric
+		return nil
+	}
+	c.index[key] = len(c.metrics)
+	c.metrics = append(c.metrics, constMetric)
+	return nil
+}
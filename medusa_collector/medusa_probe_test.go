@@ -0,0 +1,102 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestProbeHandler(t *testing.T) {
+	resetStagingMetrics()
+	medusaExporterBackupsParsedMetric.Reset()
+	targets := []Target{
+		{Prefix: "prod", Labels: map[string]string{"env": "production"}, Source: &fakeSource{backups: []backup{{Name: "prod_backup", BackupType: fullLabel, Started: 1697711900, Finished: 1697712000}}}},
+		{Cluster: "dev", Source: &fakeSource{errs: []error{errors.New("failed")}}},
+	}
+	server := httptest.NewServer(probeHandler(newTestConfigManager(targets, CollectionConfig{Timeout: model.Duration(time.Minute)}), logger))
+	defer server.Close()
+	tests := []struct {
+		name       string
+		target     string
+		wantCode   int
+		wantText   []string
+		unwantText []string
+	}{
+		{
+			"ProbeTarget",
+			"default/prod",
+			http.StatusOK,
+			[]string{
+				`medusa_backup_status{backup_name="prod_backup",backup_type="full",cluster="default",env="production",prefix="prod"} 0`,
+				`medusa_backup_last_duration_seconds{backup_type="full",cluster="default",env="production",prefix="prod"} 100`,
+				`medusa_exporter_status{cluster="default",env="production",prefix="prod",reason="none"} 1`,
+				`medusa_exporter_backups_parsed{cluster="default",env="production",prefix="prod"} 1`,
+			},
+			[]string{`cluster="dev"`, `medusa_exporter_build_info`},
+		},
+		{
+			"ProbeFailedTarget",
+			"dev/no-prefix",
+			http.StatusOK,
+			[]string{`medusa_exporter_status{cluster="dev",prefix="no-prefix",reason="error"} 0`},
+			[]string{`medusa_backup_status`, `prefix="prod"`, `env="production"`},
+		},
+		{"UnknownTarget", "dev/prod", http.StatusNotFound, nil, nil},
+		{"NoTarget", "", http.StatusBadRequest, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + probePath + "?target=" + tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", resp.StatusCode, tt.wantCode)
+			}
+			for _, text := range tt.wantText {
+				if !strings.Contains(string(body), text) {
+					t.Errorf("\nMetric is not found:\n%s\nin:\n%s", text, body)
+				}
+			}
+			for _, text := range tt.unwantText {
+				if strings.Contains(string(body), text) {
+					t.Errorf("\nUnexpected metric:\n%s\nin:\n%s", text, body)
+				}
+			}
+		})
+	}
+	// Probe doesn't change metrics of background collection.
+	if got := countMetrics(medusaBackupStatusMetric); got != 0 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 0)
+	}
+	if got := countMetrics(medusaExporterBackupsParsedMetric); got != 0 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 0)
+	}
+}
+
+func TestProbeCollectorReplaceMetric(t *testing.T) {
+	collector := &probeCollector{index: make(map[string]int)}
+	getTargetMetrics(
+		Target{},
+		targetResult{err: context.DeadlineExceeded},
+		0,
+		collector.setUpMetricValue,
+		logger,
+	)
+	getExporterStatusMetrics(context.DeadlineExceeded, "", "", collector.setUpMetricValue, logger)
+	if got := countMetrics(collector); got != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", got, 1)
+	}
+}
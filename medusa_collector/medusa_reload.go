@@ -0,0 +1,211 @@
+package medusa_collector
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const reloadPath = "/-/reload"
+
+var (
+	configManager      *ConfigManager
+	webEnableLifecycle bool
+)
+
+// SetConfigManager sets manager, which provides current targets
+// for '/probe' endpoint and reloads configuration via '/-/reload' endpoint.
+func SetConfigManager(manager *ConfigManager) {
+	configManager = manager
+}
+
+// SetEnableLifecycle enables '/-/reload' endpoint
+// from command line argument 'web.enable-lifecycle'.
+func SetEnableLifecycle(enabled bool) {
+	webEnableLifecycle = enabled
+}
+
+// ConfigManager applies exporter configuration on start and on reload.
+// It keeps current targets and collecting parameters.
+type ConfigManager struct {
+	load   func() (ExporterConfig, error)
+	logger *slog.Logger
+	// Serializes reloads.
+	mu    sync.Mutex
+	state atomic.Pointer[configState]
+}
+
+// Applied configuration.
+type configState struct {
+	collection CollectionConfig
+	targets    []Target
+	// Configuration of targets by target name, to find changed targets on reload.
+	configs map[string]TargetConfig
+}
+
+// NewConfigManager creates manager, which gets configuration from load function.
+// Configuration is applied by Reload.
+func NewConfigManager(load func() (ExporterConfig, error), logger *slog.Logger) *ConfigManager {
+	return &ConfigManager{
+		load:   load,
+		logger: logger,
+	}
+}
+
+// Reload loads configuration and applies it.
+// Sources are created for added and changed targets,
+// sources of removed and changed targets are closed,
+// sources of unchanged targets are kept.
+// On error the current configuration is kept.
+func (m *ConfigManager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.reload()
+	getExporterReloadMetrics(err == nil, time.Now().Unix())
+	if err != nil {
+		m.logger.Error("Reload exporter configuration failed", "err", err)
+		return err
+	}
+	m.logger.Info("Exporter configuration applied", "targets", len(m.Targets()))
+	return nil
+}
+
+func (m *ConfigManager) reload() error {
+	config, err := m.load()
+	if err != nil {
+		return err
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	current := m.state.Load()
+	if current == nil {
+		current = &configState{}
+	}
+	collection := config.Collection
+	// Collectors are registered on start for the chosen mode.
+	if current.collection.Mode != "" && collection.Mode != current.collection.Mode {
+		m.logger.Warn("Change of collection mode requires restart", "mode", current.collection.Mode)
+		collection.Mode = current.collection.Mode
+	}
+	currentTargets := make(map[string]Target, len(current.targets))
+	for _, target := range current.targets {
+		currentTargets[target.Name()] = target
+	}
+	state := &configState{
+		collection: collection,
+		targets:    make([]Target, 0, len(config.Targets)),
+		configs:    make(map[string]TargetConfig, len(config.Targets)),
+	}
+	var changedConfigs []TargetConfig
+	for _, targetConfig := range config.Targets {
+		name := Target{Cluster: targetConfig.Cluster, Prefix: targetConfig.Prefix}.Name()
+		state.configs[name] = targetConfig
+		if !reflect.DeepEqual(withoutThresholds(current.configs[name]), withoutThresholds(targetConfig)) {
+			changedConfigs = append(changedConfigs, targetConfig)
+		}
+	}
+	created, err := NewTargets(changedConfigs, m.logger)
+	if err != nil {
+		return err
+	}
+	createdTargets := make(map[string]Target, len(created))
+	for _, target := range created {
+		createdTargets[target.Name()] = target
+		m.logger.Info(
+			"Backup data source",
+			"target", target.Name(),
+			"source", target.Source.Name())
+	}
+	for _, targetConfig := range config.Targets {
+		name := Target{Cluster: targetConfig.Cluster, Prefix: targetConfig.Prefix}.Name()
+		target, ok := createdTargets[name]
+		if !ok {
+			target = currentTargets[name]
+		}
+		// Thresholds for all targets can be changed for unchanged target.
+		target.Thresholds = config.Thresholds.merge(targetConfig.Thresholds)
+		state.targets = append(state.targets, target)
+	}
+	var closed, removed []Target
+	for name, target := range currentTargets {
+		if _, ok := createdTargets[name]; ok {
+			closed = append(closed, target)
+			continue
+		}
+		if _, ok := state.configs[name]; !ok {
+			closed = append(closed, target)
+			removed = append(removed, target)
+			m.logger.Info("Target removed", "target", name)
+		}
+	}
+	if err := SetFilterConfig(config.Filters); err != nil {
+		CloseTargets(created)
+		return err
+	}
+	SetCollectConfig(CollectConfig{
+		KeepLastGood: collection.KeepLastGood,
+		Concurrency:  collection.Concurrency,
+		Timeout:      time.Duration(collection.Timeout),
+	})
+	SetRetryConfig(RetryConfig{
+		Retries:    collection.Retries,
+		Backoff:    time.Duration(collection.RetryBackoff),
+		MaxBackoff: time.Duration(collection.RetryMaxBackoff),
+	})
+	m.state.Store(state)
+	updateReloadedTargets(created, removed, m.logger)
+	// Collection, which is in progress, can still use closed sources,
+	// they remain usable after close.
+	CloseTargets(closed)
+	return nil
+}
+
+// Return target configuration without thresholds, which are applied without recreating source.
+func withoutThresholds(config TargetConfig) TargetConfig {
+	config.Thresholds = ThresholdConfig{}
+	return config
+}
+
+// Targets returns current targets.
+func (m *ConfigManager) Targets() []Target {
+	state := m.state.Load()
+	if state == nil {
+		return nil
+	}
+	return state.targets
+}
+
+// Collection returns current collecting parameters.
+func (m *ConfigManager) Collection() CollectionConfig {
+	state := m.state.Load()
+	if state == nil {
+		return CollectionConfig{}
+	}
+	return state.collection
+}
+
+// Close closes backup sources of current targets.
+func (m *ConfigManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	CloseTargets(m.Targets())
+}
+
+// Handler reloads configuration on POST request.
+func reloadHandler(manager *ConfigManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Only POST requests allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := manager.Reload(); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to reload config: %s", err), http.StatusInternalServerError)
+		}
+	})
+}
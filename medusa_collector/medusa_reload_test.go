@@ -0,0 +1,210 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// Return manager with already applied targets and collecting parameters.
+func newTestConfigManager(targets []Target, collection CollectionConfig) *ConfigManager {
+	manager := NewConfigManager(nil, logger)
+	manager.state.Store(&configState{collection: collection, targets: targets})
+	return manager
+}
+
+// Return registry, which exposes published snapshot.
+func snapshotGatherer() prometheus.Gatherer {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(snapshot)
+	return reg
+}
+
+// Return names of targets.
+func targetNames(targets []Target) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name())
+	}
+	return names
+}
+
+func TestConfigManagerReload(t *testing.T) {
+	defer func() {
+		SetCollectConfig(CollectConfig{Concurrency: 1})
+		SetRetryConfig(RetryConfig{})
+		_ = SetFilterConfig(FilterConfig{})
+	}()
+	resetStagingMetrics()
+	collection := CollectionConfig{
+		Mode:        IntervalMode,
+		Interval:    model.Duration(10 * time.Minute),
+		MinInterval: model.Duration(30 * time.Second),
+		Concurrency: 2,
+		Timeout:     model.Duration(5 * time.Minute),
+	}
+	config := ExporterConfig{
+		Targets: []TargetConfig{
+			{Cluster: "reload_a", ConfigFile: "/etc/medusa/a.ini", Source: CLISource},
+			{Cluster: "reload_b", ConfigFile: "/etc/medusa/b.ini", Source: CLISource},
+			{Cluster: "reload_c", ConfigFile: "/etc/medusa/c.ini", Source: CLISource},
+		},
+		Collection: collection,
+	}
+	var loadErr error
+	manager := NewConfigManager(func() (ExporterConfig, error) { return config, loadErr }, logger)
+	defer manager.Close()
+	if err := manager.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	before := manager.Targets()
+	// Metrics of target, which is removed on reload.
+	getExporterSuccessMetrics(true, 1697712000, "reload_b", "")
+	collectMutex.Lock()
+	getExporterStatusMetrics(nil, "reload_b", "", setUpMetricValue, logger)
+	publishSnapshot(logger)
+	collectMutex.Unlock()
+	// Target a is unchanged, target b is removed, target c is changed, target d is added.
+	config = ExporterConfig{
+		Targets: []TargetConfig{
+			{
+				Cluster:    "reload_a",
+				ConfigFile: "/etc/medusa/a.ini",
+				Source:     CLISource,
+				Thresholds: ThresholdConfig{RPO: map[string]model.Duration{differentialLabel: model.Duration(time.Hour)}},
+			},
+			{Cluster: "reload_c", ConfigFile: "/etc/medusa/c_new.ini", Source: CLISource},
+			{Cluster: "reload_d", ConfigFile: "/etc/medusa/d.ini", Source: CLISource},
+		},
+		Collection: collection,
+		Thresholds: ThresholdConfig{RPO: map[string]model.Duration{fullLabel: model.Duration(24 * time.Hour)}},
+	}
+	config.Collection.Mode = ScrapeMode
+	config.Collection.Concurrency = 8
+	if err := manager.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	after := manager.Targets()
+	wantNames := []string{"reload_a/no-prefix", "reload_c/no-prefix", "reload_d/no-prefix"}
+	if names := targetNames(after); !slices.Equal(names, wantNames) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", names, wantNames)
+	}
+	if after[0].Source != before[0].Source {
+		t.Errorf("\nVariables do not match:\ngot: new source for unchanged target\nwant: source is kept")
+	}
+	if after[1].Source == before[2].Source {
+		t.Errorf("\nVariables do not match:\ngot: source is kept for changed target\nwant: new source")
+	}
+	// Thresholds are applied to unchanged target.
+	wantThresholds := ThresholdConfig{RPO: map[string]model.Duration{
+		fullLabel:         model.Duration(24 * time.Hour),
+		differentialLabel: model.Duration(time.Hour),
+	}}
+	if !reflect.DeepEqual(after[0].Thresholds, wantThresholds) {
+		t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", after[0].Thresholds, wantThresholds)
+	}
+	// Collection mode requires restart.
+	if got := manager.Collection(); got.Mode != IntervalMode || got.Concurrency != 8 {
+		t.Errorf("\nVariables do not match:\ngot: mode %s, concurrency %d\nwant: mode %s, concurrency %d", got.Mode, got.Concurrency, IntervalMode, 8)
+	}
+	if got := getCollectConfig(); got.Concurrency != 8 || got.Timeout != 5*time.Minute {
+		t.Errorf("\nVariables do not match:\ngot: concurrency %d, timeout %s\nwant: concurrency %d, timeout %s", got.Concurrency, got.Timeout, 8, 5*time.Minute)
+	}
+	for name, gatherer := range map[string]prometheus.Gatherer{
+		"exporter": prometheus.DefaultGatherer,
+		"snapshot": snapshotGatherer(),
+	} {
+		if text := gatherText(t, gatherer); strings.Contains(text, `cluster="reload_b"`) {
+			t.Errorf("\nVariables do not match:\ngot: %s metrics of removed target\nwant: no metrics", name)
+		}
+	}
+	if text := gatherText(t, prometheus.DefaultGatherer); !strings.Contains(text, "medusa_exporter_config_last_reload_successful 1") {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: successful reload", text)
+	}
+	// Failed reload keeps current configuration.
+	loadErr = errors.New("failed")
+	if err := manager.Reload(); err == nil {
+		t.Errorf("\nVariables do not match:\ngot: nil error\nwant: error")
+	}
+	if names := targetNames(manager.Targets()); !slices.Equal(names, wantNames) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", names, wantNames)
+	}
+	if text := gatherText(t, prometheus.DefaultGatherer); !strings.Contains(text, "medusa_exporter_config_last_reload_successful 0") {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: failed reload", text)
+	}
+}
+
+func TestGetMedusaInfoRemovedTarget(t *testing.T) {
+	resetStagingMetrics()
+	target := Target{Cluster: "removed", Source: &fakeSource{backups: testIndexBackups()}}
+	// Target is removed on reload during collection.
+	updateReloadedTargets(nil, []Target{target}, logger)
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	if text := gatherText(t, snapshotGatherer()); strings.Contains(text, `cluster="removed"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no metrics of removed target", text)
+	}
+	// Target is added again.
+	updateReloadedTargets([]Target{target}, nil, logger)
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	if text := gatherText(t, snapshotGatherer()); !strings.Contains(text, `cluster="removed"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: metrics of added target", text)
+	}
+}
+
+func TestReloadHandler(t *testing.T) {
+	defer func() {
+		SetCollectConfig(CollectConfig{Concurrency: 1})
+		SetRetryConfig(RetryConfig{})
+		_ = SetFilterConfig(FilterConfig{})
+	}()
+	var loadErr error
+	manager := NewConfigManager(func() (ExporterConfig, error) {
+		return ExporterConfig{
+			Targets: []TargetConfig{{Cluster: "handler", Source: CLISource}},
+			Collection: CollectionConfig{
+				Mode:        IntervalMode,
+				Interval:    model.Duration(10 * time.Minute),
+				Concurrency: 1,
+			},
+		}, loadErr
+	}, logger)
+	defer manager.Close()
+	server := httptest.NewServer(reloadHandler(manager))
+	defer server.Close()
+	tests := []struct {
+		name     string
+		method   string
+		loadErr  error
+		wantCode int
+	}{
+		{"Reload", http.MethodPost, nil, http.StatusOK},
+		{"ReloadFailed", http.MethodPost, errors.New("failed"), http.StatusInternalServerError},
+		{"WrongMethod", http.MethodGet, nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadErr = tt.loadErr
+			req, err := http.NewRequest(tt.method, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("\nVariables do not match:\ngot: %d\nwant: %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package medusa_collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryConfig contains parameters for retries of getting data from Medusa.
+type RetryConfig struct {
+	// Number of retries after the first failed attempt, 0 disables retries.
+	Retries int
+	// Delay before the first retry, it's doubled for each next retry.
+	Backoff time.Duration
+	// Max delay between retries, 0 means no limit.
+	MaxBackoff time.Duration
+}
+
+var retryConfig RetryConfig
+
+// SetRetryConfig sets retry parameters
+// from command line arguments:
+// 'medusa.retries',
+// 'medusa.retry-backoff',
+// 'medusa.retry-max-backoff'
+func SetRetryConfig(config RetryConfig) {
+	settingsMutex.Lock()
+	defer settingsMutex.Unlock()
+	retryConfig = config
+}
+
+func getRetryConfig() RetryConfig {
+	settingsMutex.RLock()
+	defer settingsMutex.RUnlock()
+	return retryConfig
+}
+
+// Get backups from source with retries.
+// Metrics are not touched between attempts, so the previous snapshot
+// is exposed until all retries are exhausted.
+// Exporter gauges of getting data are set by setUpMetricValueFun, counters are global.
+func fetchBackups(ctx context.Context, source BackupSource, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) ([]backup, error) {
+	var (
+		backups []backup
+		err     error
+	)
+	config := getRetryConfig()
+	startTime := time.Now()
+	for attempt := 0; ; attempt++ {
+		getExporterAttemptMetrics(cluster, prefix)
+		backups, err = source.List(ctx, setUpMetricValueFun)
+		getExporterParseErrorMetrics(err, cluster, prefix)
+		if err == nil || attempt >= config.Retries || ctx.Err() != nil {
+			break
+		}
+		delay := returnBackoff(attempt, config.Backoff, config.MaxBackoff)
+		logger.Warn(
+			"Get data from Medusa failed, retrying",
+			"source", source.Name(),
+			"attempt", attempt+1,
+			"delay", delay,
+			"err", err,
+		)
+		if !waitBackoff(ctx, delay) {
+			err = fmt.Errorf("%w, last error: %w", ctx.Err(), err)
+			break
+		}
+	}
+	getExporterOutcomeMetrics(err == nil, cluster, prefix)
+	getExporterCollectionMetrics(time.Since(startTime), len(backups), cluster, prefix, setUpMetricValueFun, logger)
+	return backups, err
+}
+
+// Return delay before retry with exponential backoff and jitter.
+// The delay is randomly chosen between half and full backoff value,
+// so exporters started at the same time don't retry simultaneously.
+func returnBackoff(attempt int, backoff, maxBackoff time.Duration) time.Duration {
+	delay := backoff
+	for i := 0; i < attempt && (maxBackoff <= 0 || delay < maxBackoff); i++ {
+		delay *= 2
+	}
+	if maxBackoff > 0 {
+		delay = min(delay, maxBackoff)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Wait for delay, return false if context is done earlier.
+func waitBackoff(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
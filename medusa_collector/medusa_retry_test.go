@@ -0,0 +1,194 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Source which returns errors from the list and then backups.
+type fakeSource struct {
+	errs    []error
+	backups []backup
+	calls   int
+	// Delay before return.
+	delay time.Duration
+}
+
+func (s *fakeSource) List(ctx context.Context, _ setUpMetricValueFunType) ([]backup, error) {
+	// Delay is interrupted on cancellation, as for real sources.
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return s.backups, nil
+}
+
+func (s *fakeSource) Name() string {
+	return "fake"
+}
+
+func (s *fakeSource) Close() error {
+	return nil
+}
+
+func TestReturnBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		backoff    time.Duration
+		maxBackoff time.Duration
+		want       time.Duration
+	}{
+		{"FirstRetry", 0, time.Second, time.Minute, time.Second},
+		{"ThirdRetry", 2, time.Second, time.Minute, 4 * time.Second},
+		{"MaxBackoff", 10, time.Second, time.Minute, time.Minute},
+		{"NoMaxBackoff", 10, time.Second, 0, 1024 * time.Second},
+		{"NoBackoff", 3, 0, time.Minute, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Jitter is random, so check several times.
+			for range 100 {
+				got := returnBackoff(tt.attempt, tt.backoff, tt.maxBackoff)
+				if got < tt.want/2 || got > tt.want {
+					t.Fatalf("\nVariables do not match:\ngot: %v\nwant between: %v and %v", got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchBackups(t *testing.T) {
+	testBackups := []backup{{Name: "test_backup"}}
+	errTransient := errors.New("transient error")
+	tests := []struct {
+		name      string
+		config    RetryConfig
+		errs      []error
+		want      []backup
+		wantErr   bool
+		wantCalls int
+		testText  string
+	}{
+		{
+			"SuccessFirstAttempt",
+			RetryConfig{Retries: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			nil,
+			testBackups,
+			false,
+			1,
+			`medusa_exporter_get_data_attempts_total{cluster="default",prefix="no-prefix"} 1
+# HELP medusa_exporter_get_data_total Number of getting data from Medusa by final outcome after retries.
+# TYPE medusa_exporter_get_data_total counter
+medusa_exporter_get_data_total{cluster="default",outcome="failure",prefix="no-prefix"} 0
+medusa_exporter_get_data_total{cluster="default",outcome="success",prefix="no-prefix"} 1
+`,
+		},
+		{
+			"SuccessAfterRetries",
+			RetryConfig{Retries: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			[]error{errTransient, errTransient},
+			testBackups,
+			false,
+			3,
+			`medusa_exporter_get_data_attempts_total{cluster="default",prefix="no-prefix"} 3
+# HELP medusa_exporter_get_data_total Number of getting data from Medusa by final outcome after retries.
+# TYPE medusa_exporter_get_data_total counter
+medusa_exporter_get_data_total{cluster="default",outcome="failure",prefix="no-prefix"} 0
+medusa_exporter_get_data_total{cluster="default",outcome="success",prefix="no-prefix"} 1
+`,
+		},
+		{
+			"RetriesExhausted",
+			RetryConfig{Retries: 1, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+			[]error{errTransient, errTransient},
+			nil,
+			true,
+			2,
+			`medusa_exporter_get_data_attempts_total{cluster="default",prefix="no-prefix"} 2
+# HELP medusa_exporter_get_data_total Number of getting data from Medusa by final outcome after retries.
+# TYPE medusa_exporter_get_data_total counter
+medusa_exporter_get_data_total{cluster="default",outcome="failure",prefix="no-prefix"} 1
+medusa_exporter_get_data_total{cluster="default",outcome="success",prefix="no-prefix"} 0
+`,
+		},
+		{
+			"NoRetries",
+			RetryConfig{},
+			[]error{errTransient},
+			nil,
+			true,
+			1,
+			`medusa_exporter_get_data_attempts_total{cluster="default",prefix="no-prefix"} 1
+# HELP medusa_exporter_get_data_total Number of getting data from Medusa by final outcome after retries.
+# TYPE medusa_exporter_get_data_total counter
+medusa_exporter_get_data_total{cluster="default",outcome="failure",prefix="no-prefix"} 1
+medusa_exporter_get_data_total{cluster="default",outcome="success",prefix="no-prefix"} 0
+`,
+		},
+	}
+	defer SetRetryConfig(RetryConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			medusaExporterAttemptsMetric.Reset()
+			medusaExporterOutcomesMetric.Reset()
+			initExporterTargetMetrics("", "")
+			SetRetryConfig(tt.config)
+			source := &fakeSource{errs: tt.errs, backups: testBackups}
+			got, err := fetchBackups(context.Background(), source, "", "", setUpMetricValue, logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || source.calls != tt.wantCalls {
+				t.Errorf("\nVariables do not match:\ngot: %v, %d calls\nwant: %v, %d calls", got, source.calls, tt.want, tt.wantCalls)
+			}
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(medusaExporterAttemptsMetric, medusaExporterOutcomesMetric)
+			metricFamily, err := reg.Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			for _, mf := range metricFamily {
+				if _, err := expfmt.MetricFamilyToText(out, mf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !strings.HasSuffix(out.String(), tt.testText) {
+				t.Errorf("\nVariables do not match:\n%s\nwant:\n%s", out.String(), tt.testText)
+			}
+		})
+	}
+}
+
+func TestFetchBackupsTimeout(t *testing.T) {
+	defer SetRetryConfig(RetryConfig{})
+	SetRetryConfig(RetryConfig{Retries: 5, Backoff: time.Minute, MaxBackoff: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	out := &bytes.Buffer{}
+	lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	source := &fakeSource{errs: []error{errors.New("transient error")}}
+	_, err := fetchBackups(ctx, source, "", "", setUpMetricValue, lc)
+	// Waiting for retry is interrupted by timeout, which is reported as timeout.
+	if !errors.Is(err, context.DeadlineExceeded) || source.calls != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %v, %d calls\nwant: %v, %d calls", err, source.calls, context.DeadlineExceeded, 1)
+	}
+	if !strings.Contains(out.String(), `level=WARN msg="Get data from Medusa failed, retrying" source=fake attempt=1`) {
+		t.Errorf("\nRetry message not found in log:\n%s", out.String())
+	}
+}
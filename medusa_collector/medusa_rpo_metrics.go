@@ -0,0 +1,65 @@
+package medusa_collector
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	medusaBackupRPOSecondsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_rpo_seconds",
+		Help: "Recovery point objective for full or differential backup.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupRPOCompliantMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_rpo_compliant",
+		Help: "Whether the last full or differential backup was completed within recovery point objective.",
+	},
+		[]string{
+			"backup_type",
+			"cluster",
+			"prefix"})
+)
+
+// Set backup metrics:
+//   - medusa_backup_rpo_seconds
+//   - medusa_backup_rpo_compliant
+func getBackupRPOMetrics(lastBackups lastBackupsStruct, rpo map[string]model.Duration, currentUnixTime int64, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
+	for _, lastBackup := range []backupStruct{lastBackups.differential, lastBackups.full} {
+		threshold, ok := rpo[lastBackup.backupType]
+		if !ok {
+			continue
+		}
+		setUpMetric(
+			medusaBackupRPOSecondsMetric,
+			"medusa_backup_rpo_seconds",
+			time.Duration(threshold).Seconds(),
+			setUpMetricValueFun,
+			logger,
+			lastBackup.backupType,
+			cluster,
+			prefix,
+		)
+		// Without completed backup the objective isn't met.
+		compliant := lastBackup.finished > 0 &&
+			time.Unix(currentUnixTime, 0).Sub(time.Unix(lastBackup.finished, 0)) <= time.Duration(threshold)
+		setUpMetric(
+			medusaBackupRPOCompliantMetric,
+			"medusa_backup_rpo_compliant",
+			convertBoolToFloat64(compliant),
+			setUpMetricValueFun,
+			logger,
+			lastBackup.backupType,
+			cluster,
+			prefix,
+		)
+	}
+}
@@ -0,0 +1,131 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func resetBackupRPOMetrics() {
+	medusaBackupRPOSecondsMetric.Reset()
+	medusaBackupRPOCompliantMetric.Reset()
+}
+
+func TestGetBackupRPOMetrics(t *testing.T) {
+	lastBackups := lastBackupsStruct{
+		full: backupStruct{
+			backupType: fullLabel,
+			started:    1697711900,
+			finished:   1697712000,
+		},
+		differential: backupStruct{
+			backupType: differentialLabel,
+		},
+	}
+	tests := []struct {
+		name            string
+		rpo             map[string]model.Duration
+		currentUnixTime int64
+		testText        string
+	}{
+		{"NoThresholds", nil, 1697722000, ""},
+		{
+			"Compliant",
+			map[string]model.Duration{fullLabel: model.Duration(24 * time.Hour)},
+			1697722000,
+			`# HELP medusa_backup_rpo_compliant Whether the last full or differential backup was completed within recovery point objective.
+# TYPE medusa_backup_rpo_compliant gauge
+medusa_backup_rpo_compliant{backup_type="full",cluster="default",prefix="no-prefix"} 1
+# HELP medusa_backup_rpo_seconds Recovery point objective for full or differential backup.
+# TYPE medusa_backup_rpo_seconds gauge
+medusa_backup_rpo_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 86400
+`,
+		},
+		{
+			"NotCompliant",
+			map[string]model.Duration{fullLabel: model.Duration(time.Hour), differentialLabel: model.Duration(time.Hour)},
+			1697722000,
+			`# HELP medusa_backup_rpo_compliant Whether the last full or differential backup was completed within recovery point objective.
+# TYPE medusa_backup_rpo_compliant gauge
+medusa_backup_rpo_compliant{backup_type="differential",cluster="default",prefix="no-prefix"} 0
+medusa_backup_rpo_compliant{backup_type="full",cluster="default",prefix="no-prefix"} 0
+# HELP medusa_backup_rpo_seconds Recovery point objective for full or differential backup.
+# TYPE medusa_backup_rpo_seconds gauge
+medusa_backup_rpo_seconds{backup_type="differential",cluster="default",prefix="no-prefix"} 3600
+medusa_backup_rpo_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 3600
+`,
+		},
+		{
+			"AgeEqualToThreshold",
+			map[string]model.Duration{fullLabel: model.Duration(10000 * time.Second)},
+			1697722000,
+			`# HELP medusa_backup_rpo_compliant Whether the last full or differential backup was completed within recovery point objective.
+# TYPE medusa_backup_rpo_compliant gauge
+medusa_backup_rpo_compliant{backup_type="full",cluster="default",prefix="no-prefix"} 1
+# HELP medusa_backup_rpo_seconds Recovery point objective for full or differential backup.
+# TYPE medusa_backup_rpo_seconds gauge
+medusa_backup_rpo_seconds{backup_type="full",cluster="default",prefix="no-prefix"} 10000
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetBackupRPOMetrics()
+			getBackupRPOMetrics(lastBackups, tt.rpo, tt.currentUnixTime, "", "", setUpMetricValue, logger)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(
+				medusaBackupRPOSecondsMetric,
+				medusaBackupRPOCompliantMetric,
+			)
+			if got := gatherText(t, reg); got != tt.testText {
+				t.Errorf("\nVariables do not match, metrics:\n%s\nwant:\n%s", got, tt.testText)
+			}
+		})
+	}
+}
+
+func TestGetMedusaInfoRPO(t *testing.T) {
+	resetStagingMetrics()
+	target := Target{
+		Cluster:    "rpo",
+		Thresholds: ThresholdConfig{RPO: map[string]model.Duration{fullLabel: model.Duration(time.Hour)}},
+		Source:     &fakeSource{backups: testIndexBackups()},
+	}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	text := gatherText(t, snapshotGatherer())
+	for _, want := range []string{
+		`medusa_backup_rpo_seconds{backup_type="full",cluster="rpo",prefix="no-prefix"} 3600`,
+		`medusa_backup_rpo_compliant{backup_type="full",cluster="rpo",prefix="no-prefix"} 0`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", text, want)
+		}
+	}
+	if strings.Contains(text, `medusa_backup_rpo_seconds{backup_type="differential",cluster="rpo"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no metrics for backup type without threshold", text)
+	}
+}
+
+func TestGetMedusaInfoRPOFailedCollection(t *testing.T) {
+	resetStagingMetrics()
+	target := Target{
+		Cluster:    "rpo_failed",
+		Thresholds: ThresholdConfig{RPO: map[string]model.Duration{fullLabel: model.Duration(time.Hour)}},
+		Source:     &fakeSource{errs: []error{errors.New("failed")}},
+	}
+	GetMedusaInfo(context.Background(), []Target{target}, logger)
+	text := gatherText(t, snapshotGatherer())
+	// Exporter can't get data, it doesn't mean that backups are not compliant.
+	if strings.Contains(text, `medusa_backup_rpo_compliant{backup_type="full",cluster="rpo_failed"`) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: no RPO metrics for failed collection", text)
+	}
+	want := `medusa_exporter_status{cluster="rpo_failed",prefix="no-prefix",reason="error"} 0`
+	if !strings.Contains(text, want) {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", text, want)
+	}
+}
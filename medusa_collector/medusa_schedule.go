@@ -0,0 +1,338 @@
+package medusa_collector
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Predefined cron schedules.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	cronDayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// Cron schedule with minute, hour, day of month, month and day of week fields.
+// Each field is a set of allowed values, bit i is set for value i.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Fields, which start with '*'.
+	// When both day fields are restricted, time matches if any of them matches.
+	domStar, dowStar bool
+}
+
+// Daily time window with own collection interval.
+type scheduleWindow struct {
+	// Minutes since midnight.
+	start, end int
+	interval   time.Duration
+}
+
+// Schedule of collections in interval mode.
+type collectionSchedule struct {
+	interval time.Duration
+	cron     *cronSchedule
+	windows  []scheduleWindow
+	jitter   time.Duration
+	location *time.Location
+}
+
+// Parse cron expression in standard 5 fields format or one of predefined schedules.
+func parseCron(spec string) (*cronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expression %q has %d fields, want 5", spec, len(fields))
+	}
+	var (
+		c   cronSchedule
+		err error
+	)
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	// Both 0 and 7 are Sunday.
+	if c.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+// Parse comma-separated list of values, ranges and steps for one cron field.
+func parseCronField(field string, minValue, maxValue int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+		var low, high int
+		switch {
+		case rangePart == "*":
+			low, high = minValue, maxValue
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseCronValue(lowPart, names); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(highPart, names); err != nil {
+				return 0, err
+			}
+		default:
+			var err error
+			if low, err = parseCronValue(rangePart, names); err != nil {
+				return 0, err
+			}
+			high = low
+			// Value with step means range till max value, e.g. '5/15'.
+			if hasStep {
+				high = maxValue
+			}
+		}
+		if low < minValue || high > maxValue || low > high {
+			return 0, fmt.Errorf("range %q is out of %d-%d", rangePart, minValue, maxValue)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// Parse number or name of one cron value.
+func parseCronValue(value string, names map[string]int) (int, error) {
+	if number, ok := names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return number, nil
+}
+
+// Return the first time after t, which matches schedule, in location of t.
+// Zero time is returned if there is no such time within 5 years.
+func (c *cronSchedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			// Add duration instead of setting hour, so time isn't moved back on DST change.
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// Return true if day of t matches day of month and day of week fields.
+func (c *cronSchedule) matchDay(t time.Time) bool {
+	domMatch := c.dom&(1<<t.Day()) != 0
+	dowMatch := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Parse time of day in 'HH:MM' format to minutes since midnight.
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Return true if time of day of t is within window.
+// Window, which ends before its start, ends on the next day.
+func (w scheduleWindow) contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return minutes >= w.start && minutes < w.end
+	}
+	return minutes >= w.start || minutes < w.end
+}
+
+// Return schedule for collecting parameters and all found errors.
+// Schedule with valid parameters is returned even if there are errors.
+func newCollectionSchedule(c CollectionConfig) (collectionSchedule, []error) {
+	var errs []error
+	schedule := collectionSchedule{
+		interval: time.Duration(c.Interval),
+		jitter:   time.Duration(c.Jitter),
+		location: time.Local,
+	}
+	if c.Schedule != "" {
+		cron, err := parseCron(c.Schedule)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("schedule: %w", err))
+		case cron.next(time.Now()).IsZero():
+			errs = append(errs, fmt.Errorf("schedule: expression %q never matches", c.Schedule))
+		default:
+			schedule.cron = cron
+		}
+	}
+	if c.Jitter < 0 {
+		errs = append(errs, fmt.Errorf("jitter: negative value %s", c.Jitter))
+		schedule.jitter = 0
+	}
+	if c.TimeZone != "" {
+		location, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("timezone: %w", err))
+		} else {
+			schedule.location = location
+		}
+	}
+	for i, window := range c.Windows {
+		var windowErrs []error
+		start, err := parseTimeOfDay(window.Start)
+		if err != nil {
+			windowErrs = append(windowErrs, fmt.Errorf("start: %w", err))
+		}
+		end, err := parseTimeOfDay(window.End)
+		if err != nil {
+			windowErrs = append(windowErrs, fmt.Errorf("end: %w", err))
+		}
+		if len(windowErrs) == 0 && start == end {
+			windowErrs = append(windowErrs, fmt.Errorf("end: value %s is equal to start", window.End))
+		}
+		if time.Duration(window.Interval) < time.Second {
+			windowErrs = append(windowErrs, fmt.Errorf("interval: value %s is less than 1s", window.Interval))
+		}
+		for _, err := range windowErrs {
+			errs = append(errs, fmt.Errorf("windows[%d]: %w", i, err))
+		}
+		if len(windowErrs) == 0 {
+			schedule.windows = append(schedule.windows, scheduleWindow{start: start, end: end, interval: time.Duration(window.Interval)})
+		}
+	}
+	return schedule, errs
+}
+
+// Return time of the next collection after collection planned at last time,
+// without jitter. Zero last time means that there was no planned collection yet.
+// Within window collections are planned every window interval and
+// collection is planned at the start of each window.
+// Outside windows cron schedule or interval is used.
+// Collections missed while previous collection was running are skipped.
+func (s collectionSchedule) next(last, now time.Time) time.Time {
+	if last.IsZero() || last.After(now) {
+		last = now
+	}
+	last = last.In(s.location)
+	var next time.Time
+	if window, ok := s.window(last); ok {
+		next = advance(last, window.interval, now)
+	} else if s.cron != nil {
+		next = s.cron.next(now.In(s.location))
+	} else {
+		next = advance(last, s.interval, now)
+	}
+	if start, ok := s.nextWindowStart(last); ok && (next.IsZero() || start.Before(next)) {
+		next = start
+	}
+	// Schedule which never matches is rejected on validation.
+	if next.IsZero() {
+		next = advance(last, s.interval, now)
+	}
+	return next
+}
+
+// Return random delay for the next collection within jitter.
+func (s collectionSchedule) delay() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return rand.N(s.jitter)
+}
+
+// Return the first window, which contains t.
+func (s collectionSchedule) window(t time.Time) (scheduleWindow, bool) {
+	for _, window := range s.windows {
+		if window.contains(t) {
+			return window, true
+		}
+	}
+	return scheduleWindow{}, false
+}
+
+// Return the nearest start of window after t.
+func (s collectionSchedule) nextWindowStart(t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, window := range s.windows {
+		start := time.Date(t.Year(), t.Month(), t.Day(), window.start/60, window.start%60, 0, 0, s.location)
+		if !start.After(t) {
+			start = time.Date(t.Year(), t.Month(), t.Day()+1, window.start/60, window.start%60, 0, 0, s.location)
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Return the first time after now, which is last time plus a multiple of interval.
+func advance(last time.Time, interval time.Duration, now time.Time) time.Time {
+	next := last.Add(interval)
+	if next.After(now) {
+		return next
+	}
+	return last.Add((now.Sub(last)/interval + 1) * interval)
+}
@@ -0,0 +1,227 @@
+package medusa_collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{"EveryMinute", "* * * * *", ""},
+		{"ListsRangesSteps", "0,30 1-3,22 */2 jan-jun mon-fri", ""},
+		{"ValueWithStep", "5/15 * * * *", ""},
+		{"SundayAsSeven", "0 0 * * 7", ""},
+		{"Descriptor", "@daily", ""},
+		{"WrongFieldsNumber", "0 * * *", "has 4 fields, want 5"},
+		{"OutOfRange", "60 * * * *", `minute: range "60" is out of 0-59`},
+		{"InvertedRange", "* 5-1 * * *", `hour: range "5-1" is out of 0-23`},
+		{"InvalidStep", "*/0 * * * *", `minute: invalid step "0"`},
+		{"InvalidValue", "* * * foo *", `month: invalid value "foo"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCron(tt.spec)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("\nVariables do not match:\ngot error: %v\nwant error: %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// Thursday.
+	from := time.Date(2023, 10, 19, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{"EveryMinute", "* * * * *", time.Date(2023, 10, 19, 12, 35, 0, 0, time.UTC)},
+		{"EveryFiveMinutes", "*/5 * * * *", time.Date(2023, 10, 19, 12, 35, 0, 0, time.UTC)},
+		{"Hourly", "@hourly", time.Date(2023, 10, 19, 13, 0, 0, 0, time.UTC)},
+		{"BackupWindow", "*/5 1-3 * * *", time.Date(2023, 10, 20, 1, 0, 0, 0, time.UTC)},
+		{"Weekday", "0 2 * * sun", time.Date(2023, 10, 22, 2, 0, 0, 0, time.UTC)},
+		{"SundayAsSeven", "0 2 * * 7", time.Date(2023, 10, 22, 2, 0, 0, 0, time.UTC)},
+		{"DayOfMonthOrWeekday", "0 0 1 * mon", time.Date(2023, 10, 23, 0, 0, 0, 0, time.UTC)},
+		{"NextYear", "0 0 1 jan *", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"LeapDay", "0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"NeverMatches", "0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cron, err := parseCron(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cron.next(from); !got.Equal(tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNextDST(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	cron, err := parseCron("30 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks are moved back from 03:00 to 02:00 on 2023-10-29.
+	from := time.Date(2023, 10, 29, 2, 40, 0, 0, location)
+	var got []time.Time
+	for range 3 {
+		from = cron.next(from)
+		got = append(got, from)
+	}
+	for i := 1; i < len(got); i++ {
+		if diff := got[i].Sub(got[i-1]); diff != time.Hour {
+			t.Errorf("\nVariables do not match:\ngot: %v\nwant: collections every hour", got)
+		}
+	}
+}
+
+func TestCollectionScheduleNext(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2023, 10, 19, hour, minute, 0, 0, time.UTC)
+	}
+	windows := []CollectionWindow{
+		{Start: "01:00", End: "04:00", Interval: model.Duration(5 * time.Minute)},
+		{Start: "23:00", End: "00:30", Interval: model.Duration(10 * time.Minute)},
+	}
+	tests := []struct {
+		name       string
+		collection CollectionConfig
+		last       time.Time
+		now        time.Time
+		want       time.Time
+	}{
+		{
+			"FirstCollection",
+			CollectionConfig{Interval: model.Duration(time.Hour)},
+			time.Time{},
+			day(12, 0),
+			day(13, 0),
+		},
+		{
+			"IntervalWithoutDrift",
+			CollectionConfig{Interval: model.Duration(time.Hour)},
+			day(12, 0),
+			day(12, 3),
+			day(13, 0),
+		},
+		{
+			"MissedIntervals",
+			CollectionConfig{Interval: model.Duration(10 * time.Minute)},
+			day(12, 0),
+			day(12, 25),
+			day(12, 30),
+		},
+		{
+			"Cron",
+			CollectionConfig{Interval: model.Duration(time.Hour), Schedule: "15 */2 * * *"},
+			day(10, 15),
+			day(10, 20),
+			day(12, 15),
+		},
+		{
+			"InWindow",
+			CollectionConfig{Interval: model.Duration(time.Hour), Windows: windows},
+			day(2, 0),
+			day(2, 1),
+			day(2, 5),
+		},
+		{
+			"WindowStart",
+			CollectionConfig{Interval: model.Duration(time.Hour), Windows: windows},
+			day(0, 40),
+			day(0, 41),
+			day(1, 0),
+		},
+		{
+			"AfterWindow",
+			CollectionConfig{Interval: model.Duration(time.Hour), Windows: windows},
+			day(3, 55),
+			day(3, 56),
+			day(4, 0),
+		},
+		{
+			"OutsideWindow",
+			CollectionConfig{Interval: model.Duration(time.Hour), Windows: windows},
+			day(4, 0),
+			day(4, 1),
+			day(5, 0),
+		},
+		{
+			"WindowOverMidnight",
+			CollectionConfig{Interval: model.Duration(time.Hour), Windows: windows},
+			day(23, 55),
+			day(23, 56),
+			time.Date(2023, 10, 20, 0, 5, 0, 0, time.UTC),
+		},
+		{
+			"CronWithWindow",
+			CollectionConfig{Interval: model.Duration(time.Hour), Schedule: "0 */6 * * *", Windows: windows},
+			day(18, 0),
+			day(18, 1),
+			day(23, 0),
+		},
+		{
+			"TimeZone",
+			CollectionConfig{Interval: model.Duration(time.Hour), Schedule: "0 2 * * *", TimeZone: "Europe/Berlin"},
+			day(12, 0),
+			day(12, 1),
+			time.Date(2023, 10, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Windows and cron are evaluated in local time zone by default.
+			if tt.collection.TimeZone == "" {
+				tt.collection.TimeZone = "UTC"
+			}
+			schedule, errs := newCollectionSchedule(tt.collection)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if got := schedule.next(tt.last, tt.now); !got.Equal(tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectionScheduleDelay(t *testing.T) {
+	schedule, _ := newCollectionSchedule(CollectionConfig{Jitter: model.Duration(time.Minute)})
+	for range 100 {
+		if delay := schedule.delay(); delay < 0 || delay >= time.Minute {
+			t.Fatalf("\nVariables do not match:\ngot: %s\nwant: delay in [0s, 1m)", delay)
+		}
+	}
+	schedule, _ = newCollectionSchedule(CollectionConfig{})
+	if delay := schedule.delay(); delay != 0 {
+		t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", delay, time.Duration(0))
+	}
+}
+
+func TestCollectionLoopSchedule(t *testing.T) {
+	manager := newTestConfigManager(nil, CollectionConfig{Interval: model.Duration(time.Hour)})
+	loop := NewCollectionLoop(manager, logger)
+	before := time.Now()
+	planned, next := loop.schedule(time.Time{})
+	if !planned.Equal(next) || planned.Before(before.Add(time.Hour)) || planned.After(time.Now().Add(time.Hour)) {
+		t.Errorf("\nVariables do not match:\ngot: planned %s, next %s\nwant: in an hour", planned, next)
+	}
+	if got := getGaugeValue(t, medusaExporterNextCollectionMetric); got != float64(next.Unix()) {
+		t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, float64(next.Unix()))
+	}
+}
@@ -0,0 +1,82 @@
+package medusa_collector
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collection modes.
+const (
+	// IntervalMode gets data from Medusa in background with fixed interval.
+	IntervalMode = "interval"
+	// ScrapeMode gets data from Medusa on scrape.
+	ScrapeMode = "scrape"
+)
+
+// ScrapeCollector gets data from Medusa on scrape.
+// Results are cached for min interval and concurrent scrapes
+// share one getting data from Medusa.
+type ScrapeCollector struct {
+	// Collections are canceled when ctx is canceled.
+	ctx context.Context
+	// Provides current targets and collecting parameters.
+	manager     *ConfigManager
+	logger      *slog.Logger
+	mu          sync.Mutex
+	lastCollect time.Time
+	// Closed when in-flight collection is done, nil if there is no collection.
+	inFlight chan struct{}
+}
+
+// NewScrapeCollector creates collector for scrape-time collection mode.
+// Targets, min interval and timeout are taken from current configuration of manager.
+// Collections are canceled when ctx is canceled, e.g. on shutdown.
+func NewScrapeCollector(ctx context.Context, manager *ConfigManager, logger *slog.Logger) *ScrapeCollector {
+	return &ScrapeCollector{
+		ctx:     ctx,
+		manager: manager,
+		logger:  logger,
+	}
+}
+
+// Describe sends nothing, so the collector is unchecked.
+func (c *ScrapeCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// Collect gets data from Medusa if cached data is older than min interval
+// and sends metrics from the last snapshot.
+func (c *ScrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.refresh()
+	snapshot.Collect(ch)
+}
+
+func (c *ScrapeCollector) refresh() {
+	collection := c.manager.Collection()
+	c.mu.Lock()
+	if c.inFlight != nil {
+		// Wait for collection started by another scrape.
+		done := c.inFlight
+		c.mu.Unlock()
+		<-done
+		return
+	}
+	if !c.lastCollect.IsZero() && time.Since(c.lastCollect) < time.Duration(collection.MinInterval) {
+		c.mu.Unlock()
+		c.logger.Debug("Use cached metrics", "last_collect", c.lastCollect)
+		return
+	}
+	done := make(chan struct{})
+	c.inFlight = done
+	c.mu.Unlock()
+	// Collection isn't bound to scrape, so it isn't canceled
+	// when one of the waiting scrapes is canceled.
+	GetMedusaInfo(c.ctx, c.manager.Targets(), c.logger)
+	c.mu.Lock()
+	c.lastCollect = time.Now()
+	c.inFlight = nil
+	c.mu.Unlock()
+	close(done)
+}
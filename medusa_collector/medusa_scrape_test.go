@@ -0,0 +1,60 @@
+package medusa_collector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func TestScrapeCollector(t *testing.T) {
+	tests := []struct {
+		name        string
+		minInterval time.Duration
+		scrapes     int
+		wantCalls   int
+	}{
+		{"ConcurrentScrapes", time.Hour, 10, 1},
+		{"ScrapesAfterMinInterval", 0, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeSource{backups: testIndexBackups(), delay: 50 * time.Millisecond}
+			collector := NewScrapeCollector(
+				context.Background(),
+				newTestConfigManager(
+					[]Target{{Source: source}},
+					CollectionConfig{MinInterval: model.Duration(tt.minInterval), Timeout: model.Duration(time.Minute)},
+				),
+				logger,
+			)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(collector)
+			var wg sync.WaitGroup
+			for range tt.scrapes {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := reg.Gather(); err != nil {
+						t.Error(err)
+					}
+				}()
+				// Scrapes after min interval are sequential.
+				if tt.minInterval == 0 {
+					wg.Wait()
+				}
+			}
+			wg.Wait()
+			if source.calls != tt.wantCalls {
+				t.Errorf("\nVariables do not match:\ngot: %d calls\nwant: %d calls", source.calls, tt.wantCalls)
+			}
+			// Metrics are returned from cache.
+			if got := countMetrics(collector); got == 0 || got != countMetrics(snapshot) {
+				t.Errorf("\nVariables do not match:\ngot: %d metrics\nwant: %d metrics", got, countMetrics(snapshot))
+			}
+		})
+	}
+}
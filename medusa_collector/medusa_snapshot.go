@@ -0,0 +1,199 @@
+package medusa_collector
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	// Registry for metrics which are reset and populated on each collection.
+	// They are never scraped directly, only via published snapshot.
+	stagingRegistry = prometheus.NewRegistry()
+	// Serializes populating of staging metrics and publishing of snapshot.
+	collectMutex sync.Mutex
+	snapshot     = &snapshotCollector{}
+	// Additional labels of targets by target name.
+	snapshotLabels = make(map[string]map[string]string)
+	// Names of targets removed on reload. Results of collection,
+	// which was in progress during reload, are dropped for them.
+	removedTargets = make(map[string]bool)
+)
+
+// Metrics which are populated on each collection.
+var stagingMetrics = []*prometheus.GaugeVec{
+	medusaBackupInfoMetric,
+	medusaBackupStatusMetric,
+	medusaBackupDurationMetric,
+	medusaBackupDatabaseSizeMetric,
+	medusaBackupObjectsMetric,
+	medusaBackupNodesMetric,
+	medusaBackupIncompleteNodesMetric,
+	medusaBackupMissingNodesMetric,
+	medusaNodeBackupsInfosMetric,
+	medusaNodeBackupsStatusMetric,
+	medusaNodeBackupDurationMetric,
+	medusaNodeBackupsSizeMetric,
+	medusaNodeBackupsObjectsMetric,
+	medusaBackupSinceLastCompletionSecondsMetric,
+	medusaBackupLastDurationMetric,
+	medusaBackupLastDatabaseSizeMetric,
+	medusaBackupLastObjectsMetric,
+	medusaBackupRPOSecondsMetric,
+	medusaBackupRPOCompliantMetric,
+	medusaBackupScheduleExpectedMetric,
+	medusaBackupScheduleMissedMetric,
+	medusaBackupScheduleLateMetric,
+	medusaBackupScheduleExtraMetric,
+	medusaBackupRunningSecondsMetric,
+	medusaBackupStalledMetric,
+	medusaNodeBackupRunningSecondsMetric,
+	medusaNodeBackupStalledMetric,
+	medusaBackupInventoryBackupsMetric,
+	medusaBackupInventoryCompleteMetric,
+	medusaBackupInventoryIncompleteMetric,
+	medusaBackupInventoryOldestMetric,
+	medusaBackupInventoryNewestMetric,
+	medusaBackupInventoryReferencedSizeMetric,
+	medusaBackupInventoryReferencedObjectsMetric,
+	medusaBackupInventoryStoredSizeMetric,
+	medusaBackupInventoryStoredObjectsMetric,
+	medusaExporterStatusMetric,
+}
+
+func init() {
+	for _, metric := range stagingMetrics {
+		stagingRegistry.MustRegister(metric)
+	}
+}
+
+// Delete series of metrics with cluster and prefix labels.
+func resetTargetMetrics(metrics []*prometheus.GaugeVec, cluster, prefix string) {
+	for _, metric := range metrics {
+		metric.DeletePartialMatch(targetLabels(cluster, prefix))
+	}
+}
+
+// Return cluster and prefix labels of target.
+func targetLabels(cluster, prefix string) prometheus.Labels {
+	if cluster == "" {
+		cluster = defaultClusterLabel
+	}
+	if prefix == "" {
+		prefix = noPrefixLabel
+	}
+	return prometheus.Labels{"cluster": cluster, "prefix": prefix}
+}
+
+// Delete metrics of targets removed on reload and publish snapshot without them.
+// Added targets are unmarked, because target can be removed and added again.
+func updateReloadedTargets(added, removed []Target, logger *slog.Logger) {
+	collectMutex.Lock()
+	defer collectMutex.Unlock()
+	for _, target := range added {
+		delete(removedTargets, target.Name())
+	}
+	if len(removed) == 0 {
+		return
+	}
+	for _, target := range removed {
+		removedTargets[target.Name()] = true
+		delete(snapshotLabels, target.Name())
+		resetTargetMetrics(stagingMetrics, target.Cluster, target.Prefix)
+		deleteExporterTargetMetrics(target.Cluster, target.Prefix)
+		deleteTargetHealth(target)
+	}
+	publishSnapshot(logger)
+}
+
+// SnapshotCollector returns collector, which exposes metrics
+// from the last collection made by GetMedusaInfo.
+func SnapshotCollector() prometheus.Collector {
+	return snapshot
+}
+
+// Collector which exposes the last published snapshot of metrics.
+// Snapshot is replaced as a whole, so a scrape never sees
+// partially populated metrics.
+type snapshotCollector struct {
+	metrics atomic.Pointer[[]prometheus.Metric]
+}
+
+// Describe sends nothing, so the collector is unchecked.
+// The set of metrics is different for each snapshot.
+func (c *snapshotCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+func (c *snapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := c.metrics.Load()
+	if metrics == nil {
+		return
+	}
+	for _, metric := range *metrics {
+		ch <- metric
+	}
+}
+
+// Build snapshot from staging metrics and publish it.
+// Must be called with collectMutex held.
+func publishSnapshot(logger *slog.Logger) {
+	metrics, err := gatherConstMetrics(stagingRegistry, snapshotLabels)
+	if err != nil {
+		logger.Error("Build metrics snapshot failed", "err", err)
+	}
+	snapshot.metrics.Store(&metrics)
+}
+
+// Convert gathered metrics to immutable const metrics.
+// Additional labels of target are added to metrics with cluster and prefix labels.
+func gatherConstMetrics(gatherer prometheus.Gatherer, targetLabels map[string]map[string]string) ([]prometheus.Metric, error) {
+	// Registry returns gathered metrics even with error.
+	families, err := gatherer.Gather()
+	metrics := []prometheus.Metric{}
+	for _, family := range families {
+		var valueType prometheus.ValueType
+		switch family.GetType() {
+		case dto.MetricType_GAUGE:
+			valueType = prometheus.GaugeValue
+		case dto.MetricType_COUNTER:
+			valueType = prometheus.CounterValue
+		default:
+			continue
+		}
+		// All metrics of one target in the family have the same label names,
+		// because they come from the same metric vector.
+		descs := make(map[string]*prometheus.Desc)
+		for _, m := range family.GetMetric() {
+			labelNames := make([]string, 0, len(m.GetLabel()))
+			labelValues := make([]string, 0, len(m.GetLabel()))
+			target := Target{}
+			for _, label := range m.GetLabel() {
+				labelNames = append(labelNames, label.GetName())
+				labelValues = append(labelValues, label.GetValue())
+				switch label.GetName() {
+				case "cluster":
+					target.Cluster = label.GetValue()
+				case "prefix":
+					target.Prefix = label.GetValue()
+				}
+			}
+			desc, ok := descs[target.Name()]
+			if !ok {
+				desc = prometheus.NewDesc(family.GetName(), family.GetHelp(), labelNames, targetLabels[target.Name()])
+				descs[target.Name()] = desc
+			}
+			value := m.GetGauge().GetValue()
+			if valueType == prometheus.CounterValue {
+				value = m.GetCounter().GetValue()
+			}
+			metric, constErr := prometheus.NewConstMetric(desc, valueType, value, labelValues...)
+			if constErr != nil {
+				return metrics, constErr
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics, err
+}
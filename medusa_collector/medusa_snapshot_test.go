@@ -0,0 +1,75 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Return metrics in text format.
+func gatherText(t *testing.T, gatherer prometheus.Gatherer) string {
+	t.Helper()
+	metricFamily, err := gatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	for _, mf := range metricFamily {
+		if _, err := expfmt.MetricFamilyToText(out, mf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out.String()
+}
+
+// Reset metrics, which are populated on each collection.
+func resetStagingMetrics() {
+	for _, metric := range stagingMetrics {
+		metric.Reset()
+	}
+}
+
+func TestPublishSnapshot(t *testing.T) {
+	source := &fakeSource{backups: testIndexBackups()}
+	GetMedusaInfo(context.Background(), []Target{{Prefix: "prod", Source: source}}, logger)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(snapshot)
+	want := gatherText(t, stagingRegistry)
+	if want == "" {
+		t.Fatal("\nStaging metrics are empty")
+	}
+	if got := gatherText(t, reg); got != want {
+		t.Errorf("\nVariables do not match:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	// Snapshot isn't changed until the next publishing.
+	collectMutex.Lock()
+	resetStagingMetrics()
+	collectMutex.Unlock()
+	if got := gatherText(t, reg); got != want {
+		t.Errorf("\nSnapshot is changed after reset:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSnapshotConcurrentScrape(t *testing.T) {
+	source := &fakeSource{backups: testIndexBackups()}
+	GetMedusaInfo(context.Background(), []Target{{Source: source}}, logger)
+	want := countMetrics(snapshot)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			GetMedusaInfo(context.Background(), []Target{{Source: source}}, logger)
+		}
+	}()
+	for range 50 {
+		if got := countMetrics(snapshot); got != want {
+			t.Errorf("\nPartial snapshot is collected:\ngot: %d metrics\nwant: %d metrics", got, want)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,185 @@
+package medusa_collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Sources of backup data.
+const (
+	// CLISource runs 'medusa list-backups' command.
+	CLISource = "cli"
+	// StorageSource reads Medusa backup index directly from shared storage.
+	StorageSource = "storage"
+	// GRPCSource requests backups from Medusa gRPC server.
+	GRPCSource = "grpc"
+)
+
+// SourceConfig contains parameters for backup source.
+type SourceConfig struct {
+	// Source name: cli, storage or grpc.
+	Source string
+	// Name of cluster, used as cluster label of source metrics.
+	Cluster string
+	// Medusa configuration file, used by cli and storage sources.
+	ConfigFile string
+	// Prefix for shared storage, used by cli and storage sources.
+	Prefix string
+	// Medusa gRPC server parameters, used by grpc source.
+	GRPC GRPCConfig
+}
+
+// ClusterConfig contains Medusa configuration file for one cluster.
+type ClusterConfig struct {
+	// Name of cluster, used as cluster label.
+	Name string
+	// Medusa configuration file.
+	ConfigFile string
+}
+
+// ParseClusterConfigs parses values of command line argument
+// 'medusa.config-file' in '[name=]path' format.
+// Value without name is used for cluster with default name.
+// Without values one cluster with default Medusa configuration file is returned.
+func ParseClusterConfigs(values []string) ([]ClusterConfig, error) {
+	if len(values) == 0 {
+		return []ClusterConfig{{}}, nil
+	}
+	clusters := make([]ClusterConfig, 0, len(values))
+	names := make(map[string]bool, len(values))
+	for _, value := range values {
+		var cluster ClusterConfig
+		name, file, found := strings.Cut(value, "=")
+		// Path can contain '=', name can't contain '/'.
+		if found && !strings.Contains(name, "/") {
+			cluster = ClusterConfig{Name: name, ConfigFile: file}
+			if name == "" {
+				return nil, fmt.Errorf("empty cluster name in %q", value)
+			}
+		} else {
+			cluster = ClusterConfig{ConfigFile: value}
+		}
+		if cluster.ConfigFile == "" {
+			return nil, fmt.Errorf("empty Medusa configuration file in %q", value)
+		}
+		if names[cluster.Name] {
+			return nil, fmt.Errorf("duplicate cluster name %q", cluster.Name)
+		}
+		names[cluster.Name] = true
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+type execCommandFunType func(ctx context.Context, name string, arg ...string) *exec.Cmd
+
+// BackupSource is a source of Medusa backup data.
+type BackupSource interface {
+	// List returns all backups available in the source.
+	// Exporter metrics of the source are set by setUpMetricValueFun.
+	List(ctx context.Context, setUpMetricValueFun setUpMetricValueFunType) ([]backup, error)
+	// Name returns the source name.
+	Name() string
+	// Close releases resources held by the source.
+	Close() error
+}
+
+// NewBackupSource creates backup source by its name.
+func NewBackupSource(config SourceConfig, logger *slog.Logger) (BackupSource, error) {
+	switch config.Source {
+	case CLISource:
+		return newCLISource(config.Cluster, config.ConfigFile, config.Prefix, exec.CommandContext, logger), nil
+	case StorageSource:
+		return newStorageSource(config.ConfigFile, config.Prefix, logger)
+	case GRPCSource:
+		return newGRPCSource(config.GRPC, logger)
+	default:
+		return nil, fmt.Errorf("unknown backup source %q", config.Source)
+	}
+}
+
+// Source which runs 'medusa list-backups --output json' command.
+type cliSource struct {
+	cluster     string
+	config      string
+	prefix      string
+	execCommand execCommandFunType
+	logger      *slog.Logger
+}
+
+func newCLISource(cluster, config, prefix string, execCommand execCommandFunType, logger *slog.Logger) *cliSource {
+	return &cliSource{
+		cluster:     cluster,
+		config:      config,
+		prefix:      prefix,
+		execCommand: execCommand,
+		logger:      logger,
+	}
+}
+
+func (s *cliSource) List(ctx context.Context, setUpMetricValueFun setUpMetricValueFunType) ([]backup, error) {
+	startTime := time.Now()
+	backupData, err := getInfoData(ctx, s.config, s.prefix, s.execCommand, s.logger)
+	getExporterCommandMetrics(time.Since(startTime), returnExitCode(err), len(backupData), s.cluster, s.prefix, setUpMetricValueFun, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	parseBackupData, err := parseResult(backupData)
+	if err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+	return parseBackupData, nil
+}
+
+func (s *cliSource) Name() string {
+	return CLISource
+}
+
+func (s *cliSource) Close() error {
+	return nil
+}
+
+// Source which reads Medusa backup index directly from shared storage.
+type storageSource struct {
+	bucket   storageBucket
+	prefix   string
+	provider string
+	logger   *slog.Logger
+}
+
+func newStorageSource(config, prefix string, logger *slog.Logger) (*storageSource, error) {
+	sConfig, err := readStorageConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	// Prefix from command line has priority over prefix from Medusa config.
+	if prefix == "" {
+		prefix = sConfig.prefix
+	}
+	bucket, err := newStorageBucket(sConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &storageSource{
+		bucket:   bucket,
+		prefix:   prefix,
+		provider: sConfig.provider,
+		logger:   logger,
+	}, nil
+}
+
+func (s *storageSource) List(ctx context.Context, _ setUpMetricValueFunType) ([]backup, error) {
+	return listIndexBackups(ctx, s.bucket, s.prefix, s.logger)
+}
+
+func (s *storageSource) Name() string {
+	return StorageSource + ":" + s.provider
+}
+
+func (s *storageSource) Close() error {
+	return nil
+}
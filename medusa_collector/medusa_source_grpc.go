@@ -0,0 +1,442 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// Medusa service has no protobuf package, so method names are /Medusa/<method>.
+	grpcGetBackupsMethod   = "/Medusa/GetBackups"
+	grpcBackupStatusMethod = "/Medusa/BackupStatus"
+	grpcContentType        = "application/grpc"
+	grpcTimeout            = 60 * time.Second
+	// Length-prefixed message header: compressed flag and message length.
+	grpcHeaderSize = 5
+	// Max size of response message.
+	grpcMaxMessageSize = 64 << 20
+	// Medusa StatusType enum value for completed backup.
+	grpcStatusSuccess = 1
+)
+
+// GRPCConfig contains parameters for connection to Medusa gRPC server.
+type GRPCConfig struct {
+	// Address in host:port format.
+	Address string `yaml:"address"`
+	// Use TLS for connection.
+	TLS bool `yaml:"tls"`
+	// CA certificate for server verification, system pool is used if empty.
+	CAFile string `yaml:"ca_file"`
+	// Client certificate and key for mutual TLS.
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// Server name for certificate verification, host from address is used if empty.
+	ServerName string `yaml:"server_name"`
+}
+
+// Source which requests backups from Medusa gRPC server.
+// Only unary calls without compression are used, so a minimal gRPC client
+// over HTTP/2 is enough.
+type grpcSource struct {
+	endpoint string
+	client   *http.Client
+	logger   *slog.Logger
+}
+
+// Medusa BackupSummary message:
+//
+//	message BackupSummary {
+//	  string backupName = 1;
+//	  int64 startTime = 2;
+//	  int64 finishTime = 3;
+//	  int32 totalNodes = 4;
+//	  int32 finishedNodes = 5;
+//	  repeated BackupNode nodes = 6;
+//	  StatusType status = 7;
+//	  string backupType = 8;
+//	  int64 totalSize = 9;
+//	  int64 totalObjects = 10;
+//	}
+type grpcBackupSummary struct {
+	backupName    string
+	startTime     int64
+	finishTime    int64
+	totalNodes    int64
+	finishedNodes int64
+	// Hosts of BackupNode messages.
+	nodes        []string
+	status       int64
+	backupType   string
+	totalSize    int64
+	totalObjects int64
+}
+
+// Medusa BackupNode message, only host is used:
+//
+//	message BackupNode {
+//	  string host = 1;
+//	  repeated int64 tokens = 2;
+//	  string datacenter = 3;
+//	  string rack = 4;
+//	  int32 numTokens = 5;
+//	}
+
+// Medusa BackupStatusResponse message:
+//
+//	message BackupStatusResponse {
+//	  repeated string finishedNodes = 1;
+//	  repeated string unfinishedNodes = 2;
+//	  repeated string missingNodes = 3;
+//	  ...
+//	}
+type grpcBackupStatus struct {
+	finishedNodes   []string
+	unfinishedNodes []string
+	missingNodes    []string
+}
+
+func newGRPCSource(config GRPCConfig, logger *slog.Logger) (*grpcSource, error) {
+	if config.Address == "" {
+		return nil, errors.New("medusa gRPC server address is not set")
+	}
+	protocols := new(http.Protocols)
+	transport := &http.Transport{Protocols: protocols}
+	scheme := "http"
+	if config.TLS {
+		tlsConfig, err := newGRPCTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+		protocols.SetHTTP2(true)
+		scheme = "https"
+	} else {
+		// Plaintext HTTP/2 with prior knowledge (h2c), as gRPC servers expect.
+		protocols.SetUnencryptedHTTP2(true)
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid medusa gRPC server address: %w", err)
+	}
+	return &grpcSource{
+		endpoint: scheme + "://" + config.Address,
+		client:   &http.Client{Transport: transport, Timeout: grpcTimeout},
+		logger:   logger,
+	}, nil
+}
+
+func newGRPCTLSConfig(config GRPCConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config.ServerName,
+	}
+	if config.CAFile != "" {
+		data, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func (s *grpcSource) List(ctx context.Context, _ setUpMetricValueFunType) ([]backup, error) {
+	response, err := s.invoke(ctx, grpcGetBackupsMethod, nil)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := parseGRPCGetBackups(response)
+	if err != nil {
+		return nil, fmt.Errorf("parse GetBackups response: %w", err)
+	}
+	backups := make([]backup, 0, len(summaries))
+	for _, summary := range summaries {
+		// All nodes of completed backup are finished, so node lists are known from summary.
+		if summary.completed() {
+			backups = append(backups, buildGRPCBackup(summary, grpcBackupStatus{
+				finishedNodes:   summary.nodes,
+				unfinishedNodes: []string{},
+				missingNodes:    []string{},
+			}))
+			continue
+		}
+		// BackupStatusRequest message: string backupName = 1.
+		request := protowire.AppendTag(nil, 1, protowire.BytesType)
+		request = protowire.AppendString(request, summary.backupName)
+		response, err := s.invoke(ctx, grpcBackupStatusMethod, request)
+		if err != nil {
+			return nil, fmt.Errorf("backup %s: %w", summary.backupName, err)
+		}
+		status, err := parseGRPCBackupStatus(response)
+		if err != nil {
+			return nil, fmt.Errorf("parse BackupStatus response for backup %s: %w", summary.backupName, err)
+		}
+		backups = append(backups, buildGRPCBackup(summary, status))
+	}
+	return backups, nil
+}
+
+func (s *grpcSource) Name() string {
+	return GRPCSource
+}
+
+func (s *grpcSource) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// Make unary gRPC call and return response message.
+func (s *grpcSource) invoke(ctx context.Context, method string, request []byte) ([]byte, error) {
+	body := make([]byte, grpcHeaderSize, grpcHeaderSize+len(request))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(request)))
+	body = append(body, request...)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", grpcContentType)
+	req.Header.Set("Te", "trailers")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gRPC call %s failed: %s", method, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, grpcMaxMessageSize+grpcHeaderSize+1))
+	if err != nil {
+		return nil, err
+	}
+	// Status is sent in trailers or in headers for Trailers-Only response.
+	grpcStatus, grpcMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		grpcStatus, grpcMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if grpcStatus != "0" {
+		if message, err := url.PathUnescape(grpcMessage); err == nil {
+			grpcMessage = message
+		}
+		return nil, fmt.Errorf("gRPC call %s failed: code %s: %s", method, grpcStatus, grpcMessage)
+	}
+	return parseGRPCMessage(data)
+}
+
+// Parse length-prefixed message from gRPC response body.
+func parseGRPCMessage(data []byte) ([]byte, error) {
+	if len(data) < grpcHeaderSize {
+		return nil, errors.New("gRPC response message is too short")
+	}
+	if data[0] != 0 {
+		return nil, errors.New("compressed gRPC response message is not supported")
+	}
+	length := binary.BigEndian.Uint32(data[1:grpcHeaderSize])
+	if length > grpcMaxMessageSize {
+		return nil, fmt.Errorf("gRPC response message is too large: %d bytes", length)
+	}
+	if uint32(len(data)-grpcHeaderSize) < length {
+		return nil, errors.New("gRPC response message is truncated")
+	}
+	return data[grpcHeaderSize : grpcHeaderSize+length], nil
+}
+
+// Call f for each field of protobuf message.
+// Value contains encoded field value without tag.
+func rangeProtoFields(data []byte, f func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		m := protowire.ConsumeFieldValue(num, typ, data[n:])
+		if m < 0 {
+			return protowire.ParseError(m)
+		}
+		if err := f(num, typ, data[n:n+m]); err != nil {
+			return err
+		}
+		data = data[n+m:]
+	}
+	return nil
+}
+
+// Decode value of varint field.
+// For unexpected wire type zero is returned, as for absent proto3 field.
+func protoVarint(typ protowire.Type, value []byte) int64 {
+	if typ != protowire.VarintType {
+		return 0
+	}
+	v, _ := protowire.ConsumeVarint(value)
+	return int64(v)
+}
+
+// Decode value of string, bytes or embedded message field.
+func protoBytes(typ protowire.Type, value []byte) []byte {
+	if typ != protowire.BytesType {
+		return nil
+	}
+	v, _ := protowire.ConsumeBytes(value)
+	return v
+}
+
+// Parse GetBackupsResponse message:
+//
+//	message GetBackupsResponse {
+//	  repeated BackupSummary backups = 1;
+//	  ...
+//	}
+func parseGRPCGetBackups(data []byte) ([]grpcBackupSummary, error) {
+	summaries := []grpcBackupSummary{}
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		summary, err := parseGRPCBackupSummary(protoBytes(typ, value))
+		if err != nil {
+			return err
+		}
+		summaries = append(summaries, summary)
+		return nil
+	})
+	return summaries, err
+}
+
+func parseGRPCBackupSummary(data []byte) (grpcBackupSummary, error) {
+	summary := grpcBackupSummary{nodes: []string{}}
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case 1:
+			summary.backupName = string(protoBytes(typ, value))
+		case 2:
+			summary.startTime = protoVarint(typ, value)
+		case 3:
+			summary.finishTime = protoVarint(typ, value)
+		case 4:
+			summary.totalNodes = protoVarint(typ, value)
+		case 5:
+			summary.finishedNodes = protoVarint(typ, value)
+		case 6:
+			host, err := parseGRPCBackupNode(protoBytes(typ, value))
+			if err != nil {
+				return err
+			}
+			summary.nodes = append(summary.nodes, host)
+		case 7:
+			summary.status = protoVarint(typ, value)
+		case 8:
+			summary.backupType = string(protoBytes(typ, value))
+		case 9:
+			summary.totalSize = protoVarint(typ, value)
+		case 10:
+			summary.totalObjects = protoVarint(typ, value)
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// Return host of BackupNode message.
+func parseGRPCBackupNode(data []byte) (string, error) {
+	var host string
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num == 1 {
+			host = string(protoBytes(typ, value))
+		}
+		return nil
+	})
+	return host, err
+}
+
+// Return true if backup is completed and all its nodes are listed in summary.
+// Older Medusa versions don't return nodes in summary.
+func (s grpcBackupSummary) completed() bool {
+	return s.status == grpcStatusSuccess &&
+		len(s.nodes) > 0 &&
+		int64(len(s.nodes)) == s.totalNodes &&
+		s.finishedNodes == s.totalNodes
+}
+
+func parseGRPCBackupStatus(data []byte) (grpcBackupStatus, error) {
+	status := grpcBackupStatus{
+		finishedNodes:   []string{},
+		unfinishedNodes: []string{},
+		missingNodes:    []string{},
+	}
+	err := rangeProtoFields(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		switch num {
+		case 1:
+			status.finishedNodes = append(status.finishedNodes, string(protoBytes(typ, value)))
+		case 2:
+			status.unfinishedNodes = append(status.unfinishedNodes, string(protoBytes(typ, value)))
+		case 3:
+			status.missingNodes = append(status.missingNodes, string(protoBytes(typ, value)))
+		}
+		return nil
+	})
+	return status, err
+}
+
+// Convert gRPC backup data to backup struct.
+// Medusa gRPC API doesn't return per-node details, so node timestamps, size, objects,
+// release version and server type are unknown.
+func buildGRPCBackup(summary grpcBackupSummary, status grpcBackupStatus) backup {
+	result := backup{
+		BackupType:          summary.backupType,
+		CompletedNodes:      len(status.finishedNodes),
+		IncompleteNodes:     len(status.unfinishedNodes),
+		IncompleteNodesList: []node{},
+		MissingNodes:        len(status.missingNodes),
+		MissingNodesList:    status.missingNodes,
+		Name:                summary.backupName,
+		Nodes:               []node{},
+		NumObjects:          summary.totalObjects,
+		Size:                summary.totalSize,
+		Started:             summary.startTime,
+	}
+	if result.BackupType == "" {
+		result.BackupType = fullLabel
+	}
+	if summary.status == grpcStatusSuccess {
+		result.Finished = summary.finishTime
+	}
+	for _, fqdn := range status.finishedNodes {
+		result.Nodes = append(result.Nodes, node{
+			FQDN:                fqdn,
+			ReleaseVersion:      noneLabel,
+			ServerType:          noneLabel,
+			finishedWithoutTime: true,
+		})
+	}
+	for _, fqdn := range status.unfinishedNodes {
+		result.IncompleteNodesList = append(result.IncompleteNodesList, node{
+			FQDN:           fqdn,
+			ReleaseVersion: noneLabel,
+			ServerType:     noneLabel,
+		})
+	}
+	return result
+}
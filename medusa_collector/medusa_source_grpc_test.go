@@ -0,0 +1,404 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Backup data for stand-in Medusa gRPC server.
+type testGRPCBackup struct {
+	summary grpcBackupSummary
+	status  grpcBackupStatus
+}
+
+func testGRPCBackups() []testGRPCBackup {
+	return []testGRPCBackup{
+		{
+			grpcBackupSummary{
+				backupName:   "full_1",
+				startTime:    1697711900,
+				finishTime:   1697712000,
+				status:       grpcStatusSuccess,
+				backupType:   "full",
+				totalSize:    2048,
+				totalObjects: 20,
+			},
+			grpcBackupStatus{
+				finishedNodes:   []string{"node1", "node2"},
+				unfinishedNodes: []string{},
+				missingNodes:    []string{},
+			},
+		},
+		{
+			grpcBackupSummary{
+				backupName: "diff_2",
+				startTime:  1697798300,
+				// IN_PROGRESS status.
+				status:       0,
+				backupType:   "differential",
+				totalSize:    512,
+				totalObjects: 5,
+			},
+			grpcBackupStatus{
+				finishedNodes:   []string{"node1"},
+				unfinishedNodes: []string{"node2"},
+				missingNodes:    []string{"node3"},
+			},
+		},
+	}
+}
+
+func testGRPCIndexBackups() []backup {
+	return []backup{
+		{
+			BackupType:          "full",
+			CompletedNodes:      2,
+			Finished:            1697712000,
+			IncompleteNodesList: []node{},
+			MissingNodesList:    []string{},
+			Name:                "full_1",
+			Nodes: []node{
+				{FQDN: "node1", ReleaseVersion: noneLabel, ServerType: noneLabel, finishedWithoutTime: true},
+				{FQDN: "node2", ReleaseVersion: noneLabel, ServerType: noneLabel, finishedWithoutTime: true},
+			},
+			NumObjects: 20,
+			Size:       2048,
+			Started:    1697711900,
+		},
+		{
+			BackupType:      "differential",
+			CompletedNodes:  1,
+			IncompleteNodes: 1,
+			IncompleteNodesList: []node{
+				{FQDN: "node2", ReleaseVersion: noneLabel, ServerType: noneLabel},
+			},
+			MissingNodes:     1,
+			MissingNodesList: []string{"node3"},
+			Name:             "diff_2",
+			Nodes: []node{
+				{FQDN: "node1", ReleaseVersion: noneLabel, ServerType: noneLabel, finishedWithoutTime: true},
+			},
+			NumObjects: 5,
+			Size:       512,
+			Started:    1697798300,
+		},
+	}
+}
+
+func appendProtoString(b []byte, num protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendProtoVarint(b []byte, num protowire.Number, value int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(value))
+}
+
+func encodeGRPCGetBackups(backups []testGRPCBackup) []byte {
+	var response []byte
+	for _, b := range backups {
+		var summary []byte
+		summary = appendProtoString(summary, 1, b.summary.backupName)
+		summary = appendProtoVarint(summary, 2, b.summary.startTime)
+		summary = appendProtoVarint(summary, 3, b.summary.finishTime)
+		summary = appendProtoVarint(summary, 4, int64(len(b.status.finishedNodes)+len(b.status.unfinishedNodes)+len(b.status.missingNodes)))
+		summary = appendProtoVarint(summary, 5, int64(len(b.status.finishedNodes)))
+		// As Medusa, summary contains all nodes of backup token map.
+		for _, host := range slices.Concat(b.status.finishedNodes, b.status.unfinishedNodes, b.status.missingNodes) {
+			var backupNode []byte
+			backupNode = appendProtoString(backupNode, 1, host)
+			backupNode = appendProtoVarint(backupNode, 2, -9223372036854775808)
+			backupNode = appendProtoString(backupNode, 3, "dc1")
+			summary = protowire.AppendTag(summary, 6, protowire.BytesType)
+			summary = protowire.AppendBytes(summary, backupNode)
+		}
+		summary = appendProtoVarint(summary, 7, b.summary.status)
+		summary = appendProtoString(summary, 8, b.summary.backupType)
+		summary = appendProtoVarint(summary, 9, b.summary.totalSize)
+		summary = appendProtoVarint(summary, 10, b.summary.totalObjects)
+		response = protowire.AppendTag(response, 1, protowire.BytesType)
+		response = protowire.AppendBytes(response, summary)
+	}
+	return appendProtoVarint(response, 2, grpcStatusSuccess)
+}
+
+func encodeGRPCBackupStatus(status grpcBackupStatus) []byte {
+	var response []byte
+	for _, host := range status.finishedNodes {
+		response = appendProtoString(response, 1, host)
+	}
+	for _, host := range status.unfinishedNodes {
+		response = appendProtoString(response, 2, host)
+	}
+	for _, host := range status.missingNodes {
+		response = appendProtoString(response, 3, host)
+	}
+	return appendProtoString(response, 4, "2023-10-19 10:38:20")
+}
+
+// Stand-in Medusa gRPC server.
+// It implements GetBackups and BackupStatus unary calls over HTTP/2.
+func newFakeGRPCHandler(t *testing.T, backups []testGRPCBackup) http.Handler {
+	t.Helper()
+	writeStatus := func(w http.ResponseWriter, code, message string) {
+		w.Header().Set("Grpc-Status", code)
+		w.Header().Set("Grpc-Message", url.PathEscape(message))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.Method != http.MethodPost || r.Header.Get("Content-Type") != grpcContentType {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		request, err := parseGRPCMessage(body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		w.Header().Set("Content-Type", grpcContentType)
+		var response []byte
+		switch r.URL.Path {
+		case grpcGetBackupsMethod:
+			response = encodeGRPCGetBackups(backups)
+		case grpcBackupStatusMethod:
+			backupName := ""
+			rangeProtoFields(request, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if num == 1 {
+					backupName = string(protoBytes(typ, value))
+				}
+				return nil
+			})
+			for _, b := range backups {
+				if b.summary.backupName == backupName {
+					response = encodeGRPCBackupStatus(b.status)
+				}
+			}
+			if response == nil {
+				// NOT_FOUND.
+				writeStatus(w, "5", "backup "+backupName+" does not exist")
+				return
+			}
+		default:
+			// UNIMPLEMENTED.
+			writeStatus(w, "12", "unknown method")
+			return
+		}
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		message := make([]byte, grpcHeaderSize, grpcHeaderSize+len(response))
+		binary.BigEndian.PutUint32(message[1:], uint32(len(response)))
+		w.Write(append(message, response...))
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "")
+	})
+}
+
+// Start stand-in server with plaintext HTTP/2 (h2c).
+func newFakeGRPCServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	return server
+}
+
+func TestGRPCSourceList(t *testing.T) {
+	server := newFakeGRPCServer(t, newFakeGRPCHandler(t, testGRPCBackups()))
+	defer server.Close()
+	tlsServer := httptest.NewUnstartedServer(newFakeGRPCHandler(t, testGRPCBackups()))
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw})
+	if err := os.WriteFile(caFile, caData, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		config GRPCConfig
+	}{
+		{"Plaintext", GRPCConfig{Address: server.Listener.Addr().String()}},
+		{"TLS", GRPCConfig{Address: tlsServer.Listener.Addr().String(), TLS: true, CAFile: caFile, ServerName: "example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := newGRPCSource(tt.config, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer source.Close()
+			got, err := source.List(context.Background(), setUpMetricValue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, testGRPCIndexBackups()) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, testGRPCIndexBackups())
+			}
+		})
+	}
+}
+
+func TestGRPCSourceListBackupStatusCalls(t *testing.T) {
+	var calls atomic.Int32
+	handler := newFakeGRPCHandler(t, testGRPCBackups())
+	server := newFakeGRPCServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == grpcBackupStatusMethod {
+			calls.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	source, err := newGRPCSource(GRPCConfig{Address: server.Listener.Addr().String()}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	if _, err := source.List(context.Background(), setUpMetricValue); err != nil {
+		t.Fatal(err)
+	}
+	// Node lists are requested only for unfinished backup.
+	if got := calls.Load(); got != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %d calls\nwant: %d calls", got, 1)
+	}
+}
+
+func TestGRPCBackupSummaryCompleted(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary grpcBackupSummary
+		want    bool
+	}{
+		{"Completed", grpcBackupSummary{status: grpcStatusSuccess, totalNodes: 2, finishedNodes: 2, nodes: []string{"node1", "node2"}}, true},
+		{"InProgress", grpcBackupSummary{totalNodes: 2, finishedNodes: 1, nodes: []string{"node1", "node2"}}, false},
+		{"WithoutNodes", grpcBackupSummary{status: grpcStatusSuccess, totalNodes: 2, finishedNodes: 2, nodes: []string{}}, false},
+		{"NotAllNodesFinished", grpcBackupSummary{status: grpcStatusSuccess, totalNodes: 2, finishedNodes: 1, nodes: []string{"node1", "node2"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.completed(); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCSourceListErrors(t *testing.T) {
+	// GetBackups returns backup, which is unknown for BackupStatus.
+	listed := append(testGRPCBackups(), testGRPCBackup{summary: grpcBackupSummary{backupName: "diff 3"}})
+	notFoundServer := newFakeGRPCServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == grpcGetBackupsMethod {
+			newFakeGRPCHandler(t, listed).ServeHTTP(w, r)
+			return
+		}
+		newFakeGRPCHandler(t, testGRPCBackups()).ServeHTTP(w, r)
+	}))
+	defer notFoundServer.Close()
+	notFoundHTTPServer := newFakeGRPCServer(t, http.NotFoundHandler())
+	defer notFoundHTTPServer.Close()
+	tests := []struct {
+		name    string
+		config  GRPCConfig
+		wantErr string
+	}{
+		{"GRPCStatusError", GRPCConfig{Address: notFoundServer.Listener.Addr().String()}, "backup diff 3: gRPC call /Medusa/BackupStatus failed: code 5: backup diff 3 does not exist"},
+		{"HTTPError", GRPCConfig{Address: notFoundHTTPServer.Listener.Addr().String()}, "gRPC call /Medusa/GetBackups failed: 404 Not Found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := newGRPCSource(tt.config, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer source.Close()
+			if _, err := source.List(context.Background(), setUpMetricValue); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("\nVariables do not match:\ngot error: %v\nwant error containing: %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGRPCMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"Valid", []byte{0, 0, 0, 0, 2, 8, 1, 0}, []byte{8, 1}, false},
+		{"Empty", []byte{0, 0, 0, 0, 0}, []byte{}, false},
+		{"TooShort", []byte{0, 0}, nil, true},
+		{"Compressed", []byte{1, 0, 0, 0, 2, 8, 1}, nil, true},
+		{"Truncated", []byte{0, 0, 0, 0, 3, 8, 1}, nil, true},
+		{"TooLarge", []byte{0, 0xff, 0xff, 0xff, 0xff}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGRPCMessage(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGRPCBackupStatusInvalid(t *testing.T) {
+	// Tag with field number 1 and bytes type without length.
+	if _, err := parseGRPCBackupStatus([]byte{10}); err == nil {
+		t.Errorf("\nExpected error, got nil")
+	}
+}
+
+func TestGetMedusaInfoGRPC(t *testing.T) {
+	server := newFakeGRPCServer(t, newFakeGRPCHandler(t, testGRPCBackups()))
+	defer server.Close()
+	tests := []struct {
+		name     string
+		address  string
+		testText string
+	}{
+		{"GetMedusaInfoGRPCSuccess", server.Listener.Addr().String(), ""},
+		{"GetMedusaInfoGRPCError", "127.0.0.1:1", `level=ERROR msg="Get data from Medusa failed" source=grpc`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetStagingMetrics()
+			out := &bytes.Buffer{}
+			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+			source, err := newGRPCSource(GRPCConfig{Address: tt.address}, lc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer source.Close()
+			GetMedusaInfo(context.Background(), []Target{{Source: source}}, lc)
+			if !strings.Contains(out.String(), tt.testText) {
+				t.Errorf("\nVariable do not match:\n%s\nwant:\n%s", tt.testText, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package medusa_collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewBackupSource(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "medusa.ini")
+	configData := fmt.Sprintf("[storage]\nstorage_provider = local\nbase_path = %s\nbucket_name = backup\n", dir)
+	if err := os.WriteFile(config, []byte(configData), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		config   SourceConfig
+		wantName string
+		wantErr  bool
+	}{
+		{"CLISource", SourceConfig{Source: CLISource}, "cli", false},
+		{"StorageSource", SourceConfig{Source: StorageSource, ConfigFile: config}, "storage:local", false},
+		{"StorageSourceNoConfig", SourceConfig{Source: StorageSource, ConfigFile: filepath.Join(dir, "not_exist.ini")}, "", true},
+		{"GRPCSource", SourceConfig{Source: GRPCSource, GRPC: GRPCConfig{Address: "localhost:50051"}}, "grpc", false},
+		{"GRPCSourceNoAddress", SourceConfig{Source: GRPCSource}, "", true},
+		{"GRPCSourceBadAddress", SourceConfig{Source: GRPCSource, GRPC: GRPCConfig{Address: "localhost"}}, "", true},
+		{"UnknownSource", SourceConfig{Source: "unknown"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBackupSource(tt.config, logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer got.Close()
+			if got.Name() != tt.wantName {
+				t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", got.Name(), tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParseClusterConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []ClusterConfig
+		wantErr bool
+	}{
+		{"NoValues", nil, []ClusterConfig{{}}, false},
+		{"WithoutName", []string{"/etc/medusa/medusa.ini"}, []ClusterConfig{{ConfigFile: "/etc/medusa/medusa.ini"}}, false},
+		{
+			"WithNames",
+			[]string{"prod=/etc/medusa/prod.ini", "dev=/etc/medusa/dev=1.ini"},
+			[]ClusterConfig{{"prod", "/etc/medusa/prod.ini"}, {"dev", "/etc/medusa/dev=1.ini"}},
+			false,
+		},
+		{"EqualInPath", []string{"/etc/medusa/a=b.ini"}, []ClusterConfig{{ConfigFile: "/etc/medusa/a=b.ini"}}, false},
+		{"EmptyName", []string{"=/etc/medusa/medusa.ini"}, nil, true},
+		{"EmptyFile", []string{"prod="}, nil, true},
+		{"DuplicateName", []string{"prod=/etc/medusa/a.ini", "prod=/etc/medusa/b.ini"}, nil, true},
+		{"DuplicateWithoutName", []string{"/etc/medusa/a.ini", "/etc/medusa/b.ini"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClusterConfigs(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCLISourceList(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockTestData mockStruct
+		want         []backup
+		wantErr      bool
+		wantExitCode float64
+	}{
+		{
+			"ValidJSON",
+			mockStruct{
+				`[{"backup_type":"full","completed_nodes":1,"finished":1697712000,"incomplete_nodes":0,` +
+					`"incomplete_nodes_list":[],"missing_nodes":0,"missing_nodes_list":[],"name":"test_backup",` +
+					`"nodes":[],"num_objects":100,"size":1024,"started":1697711900}]`,
+				"",
+				0,
+			},
+			[]backup{
+				{
+					BackupType:          "full",
+					CompletedNodes:      1,
+					Finished:            1697712000,
+					IncompleteNodesList: []node{},
+					MissingNodesList:    []string{},
+					Name:                "test_backup",
+					Nodes:               []node{},
+					NumObjects:          100,
+					Size:                1024,
+					Started:             1697711900,
+				},
+			},
+			false,
+			0,
+		},
+		{"InvalidJSON", mockStruct{`{invalid json}`, "", 0}, nil, true, 0},
+		{"CommandError", mockStruct{"", "ERROR: Something is wrong", 1}, nil, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockData = tt.mockTestData
+			source := newCLISource("cli", "", "", fakeExecCommand, logger)
+			defer source.Close()
+			got, err := source.List(context.Background(), setUpMetricValue)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if got := getGaugeValue(t, medusaExporterCommandExitCodeMetric.WithLabelValues("cli", noPrefixLabel)); got != tt.wantExitCode {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, tt.wantExitCode)
+			}
+			if got := getGaugeValue(t, medusaExporterCommandStdoutBytesMetric.WithLabelValues("cli", noPrefixLabel)); got != float64(len(tt.mockTestData.mockStdout)) {
+				t.Errorf("\nVariables do not match:\ngot: %v\nwant: %v", got, len(tt.mockTestData.mockStdout))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package medusa_collector
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	medusaBackupRunningSecondsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_running_seconds",
+		Help: "Time since start of unfinished backup.",
+	},
+		[]string{
+			"backup_name",
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaBackupStalledMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_backup_stalled",
+		Help: "Whether unfinished backup is running longer than stall threshold.",
+	},
+		[]string{
+			"backup_name",
+			"backup_type",
+			"cluster",
+			"prefix"})
+	medusaNodeBackupRunningSecondsMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_node_backup_running_seconds",
+		Help: "Time since start of unfinished node backup.",
+	},
+		[]string{
+			"backup_name",
+			"backup_type",
+			"cluster",
+			"node_fqdn",
+			"prefix"})
+	medusaNodeBackupStalledMetric = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "medusa_node_backup_stalled",
+		Help: "Whether unfinished node backup is running longer than stall threshold.",
+	},
+		[]string{
+			"backup_name",
+			"backup_type",
+			"cluster",
+			"node_fqdn",
+			"prefix"})
+)
+
+// Set backup metrics for unfinished backup and its unfinished nodes:
+//   - medusa_backup_running_seconds
+//   - medusa_backup_stalled
+//   - medusa_node_backup_running_seconds
+//   - medusa_node_backup_stalled
+//
+// Stalled metrics are set only if stall threshold is set.
+// Missing nodes have no start time, so metrics aren't set for them.
+func getBackupStallMetrics(backupData backup, stall model.Duration, currentUnixTime int64, cluster, prefix string, setUpMetricValueFun setUpMetricValueFunType, logger *slog.Logger) {
+	if backupData.Finished > 0 || backupData.Started <= 0 {
+		return
+	}
+	labels := targetLabels(cluster, prefix)
+	cluster, prefix = labels["cluster"], labels["prefix"]
+	running := runningSeconds(backupData.Started, currentUnixTime)
+	setUpMetric(
+		medusaBackupRunningSecondsMetric,
+		"medusa_backup_running_seconds",
+		running,
+		setUpMetricValueFun,
+		logger,
+		backupData.Name,
+		backupData.BackupType,
+		cluster,
+		prefix,
+	)
+	if stall > 0 {
+		setUpMetric(
+			medusaBackupStalledMetric,
+			"medusa_backup_stalled",
+			convertBoolToFloat64(running > time.Duration(stall).Seconds()),
+			setUpMetricValueFun,
+			logger,
+			backupData.Name,
+			backupData.BackupType,
+			cluster,
+			prefix,
+		)
+	}
+	// Completed nodes can be unfinished because of bugs in Medusa, as for node status.
+	nodes := make([]node, 0, len(backupData.Nodes)+len(backupData.IncompleteNodesList))
+	nodes = append(nodes, backupData.Nodes...)
+	nodes = append(nodes, backupData.IncompleteNodesList...)
+	for _, node := range nodes {
+		if node.Finished > 0 || node.Started <= 0 {
+			continue
+		}
+		nodeRunning := runningSeconds(node.Started, currentUnixTime)
+		setUpMetric(
+			medusaNodeBackupRunningSecondsMetric,
+			"medusa_node_backup_running_seconds",
+			nodeRunning,
+			setUpMetricValueFun,
+			logger,
+			backupData.Name,
+			backupData.BackupType,
+			cluster,
+			node.FQDN,
+			prefix,
+		)
+		if stall > 0 {
+			setUpMetric(
+				medusaNodeBackupStalledMetric,
+				"medusa_node_backup_stalled",
+				convertBoolToFloat64(nodeRunning > time.Duration(stall).Seconds()),
+				setUpMetricValueFun,
+				logger,
+				backupData.Name,
+				backupData.BackupType,
+				cluster,
+				node.FQDN,
+				prefix,
+			)
+		}
+	}
+}
+
+// Return seconds since start, start in the future is treated as now.
+func runningSeconds(started, currentUnixTime int64) float64 {
+	return float64(max(currentUnixTime-started, 0))
+}
@@ -0,0 +1,115 @@
+package medusa_collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+func resetBackupStallMetrics() {
+	medusaBackupRunningSecondsMetric.Reset()
+	medusaBackupStalledMetric.Reset()
+	medusaNodeBackupRunningSecondsMetric.Reset()
+	medusaNodeBackupStalledMetric.Reset()
+}
+
+func TestGetBackupStallMetrics(t *testing.T) {
+	unfinished := backup{
+		Name:       "backup1",
+		BackupType: fullLabel,
+		Started:    1697711000,
+		Nodes: []node{
+			{FQDN: "node1", Started: 1697711000, Finished: 1697712000},
+		},
+		IncompleteNodesList: []node{
+			{FQDN: "node2", Started: 1697711000},
+			{FQDN: "node3", Started: 1697721000},
+		},
+		MissingNodesList: []string{"node4"},
+	}
+	tests := []struct {
+		name       string
+		backupData backup
+		stall      model.Duration
+		testText   string
+	}{
+		{
+			"FinishedBackup",
+			backup{Name: "backup1", BackupType: fullLabel, Started: 1697711000, Finished: 1697712000},
+			model.Duration(time.Hour),
+			"",
+		},
+		{
+			"WithoutThreshold",
+			unfinished,
+			0,
+			`# HELP medusa_backup_running_seconds Time since start of unfinished backup.
+# TYPE medusa_backup_running_seconds gauge
+medusa_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",prefix="no-prefix"} 11000
+# HELP medusa_node_backup_running_seconds Time since start of unfinished node backup.
+# TYPE medusa_node_backup_running_seconds gauge
+medusa_node_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node2",prefix="no-prefix"} 11000
+medusa_node_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node3",prefix="no-prefix"} 1000
+`,
+		},
+		{
+			"WithThreshold",
+			unfinished,
+			model.Duration(time.Hour),
+			`# HELP medusa_backup_running_seconds Time since start of unfinished backup.
+# TYPE medusa_backup_running_seconds gauge
+medusa_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",prefix="no-prefix"} 11000
+# HELP medusa_backup_stalled Whether unfinished backup is running longer than stall threshold.
+# TYPE medusa_backup_stalled gauge
+medusa_backup_stalled{backup_name="backup1",backup_type="full",cluster="default",prefix="no-prefix"} 1
+# HELP medusa_node_backup_running_seconds Time since start of unfinished node backup.
+# TYPE medusa_node_backup_running_seconds gauge
+medusa_node_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node2",prefix="no-prefix"} 11000
+medusa_node_backup_running_seconds{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node3",prefix="no-prefix"} 1000
+# HELP medusa_node_backup_stalled Whether unfinished node backup is running longer than stall threshold.
+# TYPE medusa_node_backup_stalled gauge
+medusa_node_backup_stalled{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node2",prefix="no-prefix"} 1
+medusa_node_backup_stalled{backup_name="backup1",backup_type="full",cluster="default",node_fqdn="node3",prefix="no-prefix"} 0
+`,
+		},
+		{
+			"RunningBackup",
+			backup{Name: "backup2", BackupType: differentialLabel, Started: 1697721000},
+			model.Duration(time.Hour),
+			`# HELP medusa_backup_running_seconds Time since start of unfinished backup.
+# TYPE medusa_backup_running_seconds gauge
+medusa_backup_running_seconds{backup_name="backup2",backup_type="differential",cluster="default",prefix="no-prefix"} 1000
+# HELP medusa_backup_stalled Whether unfinished backup is running longer than stall threshold.
+# TYPE medusa_backup_stalled gauge
+medusa_backup_stalled{backup_name="backup2",backup_type="differential",cluster="default",prefix="no-prefix"} 0
+`,
+		},
+		{
+			"StartInFuture",
+			backup{Name: "backup3", BackupType: fullLabel, Started: 1697723000},
+			0,
+			`# HELP medusa_backup_running_seconds Time since start of unfinished backup.
+# TYPE medusa_backup_running_seconds gauge
+medusa_backup_running_seconds{backup_name="backup3",backup_type="full",cluster="default",prefix="no-prefix"} 0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetBackupStallMetrics()
+			getBackupStallMetrics(tt.backupData, tt.stall, 1697722000, "", "", setUpMetricValue, logger)
+			reg := prometheus.NewRegistry()
+			reg.MustRegister(
+				medusaBackupRunningSecondsMetric,
+				medusaBackupStalledMetric,
+				medusaNodeBackupRunningSecondsMetric,
+				medusaNodeBackupStalledMetric,
+			)
+			if got := gatherText(t, reg); got != tt.testText {
+				t.Errorf("\nVariables do not match, metrics:\n%s\nwant:\n%s", got, tt.testText)
+			}
+		})
+	}
+}
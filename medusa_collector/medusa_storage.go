@@ -0,0 +1,319 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	localProvider = "local"
+	indexPath     = "index/backup_index/"
+)
+
+// Object in shared storage.
+// Key is a full object path relative to the bucket root, separated by '/'.
+type storageObject struct {
+	key  string
+	size int64
+}
+
+// Minimal set of operations required to read Medusa backup index.
+type storageBucket interface {
+	// List all objects which keys start with prefix.
+	listObjects(ctx context.Context, prefix string) ([]storageObject, error)
+	// Read the content of object.
+	readObject(ctx context.Context, key string) ([]byte, error)
+}
+
+// Node backup files from Medusa backup index:
+//
+//	index/backup_index/<backup_name>/tokenmap_<fqdn>.json
+//	index/backup_index/<backup_name>/manifest_<fqdn>.json
+//	index/backup_index/<backup_name>/schema_<fqdn>.cql
+//	index/backup_index/<backup_name>/server_version_<fqdn>.json
+//	index/backup_index/<backup_name>/differential_<fqdn>
+//	index/backup_index/<backup_name>/started_<fqdn>_<timestamp>.timestamp
+//	index/backup_index/<backup_name>/finished_<fqdn>_<timestamp>.timestamp
+type nodeIndex struct {
+	differential  bool
+	finished      int64
+	manifest      string
+	serverVersion string
+	started       int64
+	tokenmap      string
+}
+
+//	[{
+//	  "keyspace": "string",
+//	  "columnfamily": "string",
+//	  "objects": [{"path": "string", "MD5": "string", "size": number}]
+//	}]
+type manifestSection struct {
+	Keyspace     string `json:"keyspace"`
+	Columnfamily string `json:"columnfamily"`
+	Objects      []struct {
+		Path string `json:"path"`
+		Size int64  `json:"size"`
+	} `json:"objects"`
+}
+
+//	{
+//	  "server_type": "string",
+//	  "release_version": "string"
+//	}
+type serverVersion struct {
+	ReleaseVersion string `json:"release_version"`
+	ServerType     string `json:"server_type"`
+}
+
+// Create storage client according to Medusa storage provider.
+func newStorageBucket(config storageConfig) (storageBucket, error) {
+	switch {
+	case config.provider == localProvider:
+		return newLocalBucket(config)
+	case isS3Provider(config.provider):
+		return newS3Bucket(config)
+	case config.provider == gcsProvider:
+		return newGCSBucket(config)
+	case config.provider == azureProvider:
+		return newAzureBucket(config)
+	default:
+		return nil, fmt.Errorf("unsupported storage provider %q", config.provider)
+	}
+}
+
+// Medusa storage prefix is a "directory" in bucket.
+func returnPrefixPath(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return strings.TrimSuffix(prefix, "/") + "/"
+}
+
+// Read Medusa backup index and build the same backup list
+// as 'medusa list-backups --output json' returns.
+func listIndexBackups(ctx context.Context, bucket storageBucket, prefix string, logger *slog.Logger) ([]backup, error) {
+	backupIndexPath := returnPrefixPath(prefix) + indexPath
+	objects, err := bucket.listObjects(ctx, backupIndexPath)
+	if err != nil {
+		return nil, err
+	}
+	index := groupIndexObjects(objects, backupIndexPath, logger)
+	backups := make([]backup, 0, len(index))
+	for backupName, nodes := range index {
+		singleBackup, err := buildBackup(ctx, bucket, backupName, nodes)
+		if err != nil {
+			return nil, fmt.Errorf("backup %s: %w", backupName, err)
+		}
+		backups = append(backups, singleBackup)
+	}
+	// Medusa returns backups sorted by start time.
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].Started == backups[j].Started {
+			return backups[i].Name < backups[j].Name
+		}
+		return backups[i].Started < backups[j].Started
+	})
+	return backups, nil
+}
+
+// Group index objects by backup name and node FQDN.
+func groupIndexObjects(objects []storageObject, backupIndexPath string, logger *slog.Logger) map[string]map[string]*nodeIndex {
+	index := make(map[string]map[string]*nodeIndex)
+	for _, object := range objects {
+		backupName, fileName, ok := strings.Cut(strings.TrimPrefix(object.key, backupIndexPath), "/")
+		if !ok || backupName == "" || fileName == "" || strings.Contains(fileName, "/") {
+			logger.Debug("Skip unknown index object", "key", object.key)
+			continue
+		}
+		fqdn, ok := parseIndexFileName(fileName)
+		if !ok {
+			logger.Debug("Skip unknown index object", "key", object.key)
+			continue
+		}
+		if _, ok := index[backupName]; !ok {
+			index[backupName] = make(map[string]*nodeIndex)
+		}
+		if _, ok := index[backupName][fqdn]; !ok {
+			index[backupName][fqdn] = &nodeIndex{}
+		}
+		fillNodeIndex(index[backupName][fqdn], fileName, object.key)
+	}
+	return index
+}
+
+// Get node FQDN from index file name.
+func parseIndexFileName(fileName string) (string, bool) {
+	switch {
+	case strings.HasPrefix(fileName, "server_version_"):
+		return trimIndexFileName(fileName, "server_version_", ".json")
+	case strings.HasPrefix(fileName, "tokenmap_"):
+		return trimIndexFileName(fileName, "tokenmap_", ".json")
+	case strings.HasPrefix(fileName, "manifest_"):
+		return trimIndexFileName(fileName, "manifest_", ".json")
+	case strings.HasPrefix(fileName, "schema_"):
+		return trimIndexFileName(fileName, "schema_", ".cql")
+	case strings.HasPrefix(fileName, "differential_"):
+		return trimIndexFileName(fileName, "differential_", "")
+	case strings.HasPrefix(fileName, "started_"), strings.HasPrefix(fileName, "finished_"):
+		fqdn, _, ok := parseTimestampFileName(fileName)
+		return fqdn, ok
+	}
+	return "", false
+}
+
+func trimIndexFileName(fileName, prefix, suffix string) (string, bool) {
+	if !strings.HasSuffix(fileName, suffix) {
+		return "", false
+	}
+	fqdn := strings.TrimSuffix(strings.TrimPrefix(fileName, prefix), suffix)
+	return fqdn, fqdn != ""
+}
+
+// Get node FQDN and timestamp from file names like:
+// started_<fqdn>_<timestamp>.timestamp
+// finished_<fqdn>_<timestamp>.timestamp
+func parseTimestampFileName(fileName string) (string, int64, bool) {
+	_, name, _ := strings.Cut(fileName, "_")
+	if !strings.HasSuffix(name, ".timestamp") {
+		return "", 0, false
+	}
+	name = strings.TrimSuffix(name, ".timestamp")
+	idx := strings.LastIndex(name, "_")
+	if idx <= 0 {
+		return "", 0, false
+	}
+	timestamp, err := strconv.ParseFloat(name[idx+1:], 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:idx], int64(timestamp), true
+}
+
+func fillNodeIndex(node *nodeIndex, fileName, key string) {
+	switch {
+	case strings.HasPrefix(fileName, "server_version_"):
+		node.serverVersion = key
+	case strings.HasPrefix(fileName, "tokenmap_"):
+		node.tokenmap = key
+	case strings.HasPrefix(fileName, "manifest_"):
+		node.manifest = key
+	case strings.HasPrefix(fileName, "differential_"):
+		node.differential = true
+	case strings.HasPrefix(fileName, "started_"):
+		_, node.started, _ = parseTimestampFileName(fileName)
+	case strings.HasPrefix(fileName, "finished_"):
+		_, node.finished, _ = parseTimestampFileName(fileName)
+	}
+}
+
+// Build cluster backup from node backups.
+// The logic is the same as in Medusa ClusterBackup:
+//   - expected nodes are taken from the tokenmap,
+//   - missing nodes are nodes from tokenmap without node backup,
+//   - backup is finished only if all nodes are finished and there are no missing nodes.
+func buildBackup(ctx context.Context, bucket storageBucket, backupName string, nodes map[string]*nodeIndex) (backup, error) {
+	result := backup{
+		BackupType:          fullLabel,
+		IncompleteNodesList: []node{},
+		MissingNodesList:    []string{},
+		Name:                backupName,
+		Nodes:               []node{},
+		objects:             []storageObject{},
+	}
+	fqdns := make([]string, 0, len(nodes))
+	for fqdn := range nodes {
+		fqdns = append(fqdns, fqdn)
+	}
+	sort.Strings(fqdns)
+	var tokenmap map[string]json.RawMessage
+	var lastFinished int64
+	for _, fqdn := range fqdns {
+		nodeData := nodes[fqdn]
+		if tokenmap == nil && nodeData.tokenmap != "" {
+			if err := readJSONObject(ctx, bucket, nodeData.tokenmap, &tokenmap); err != nil {
+				return backup{}, err
+			}
+		}
+		if nodeData.differential {
+			result.BackupType = differentialLabel
+		}
+		singleNode, objects, err := buildNode(ctx, bucket, fqdn, nodeData)
+		if err != nil {
+			return backup{}, err
+		}
+		result.objects = append(result.objects, objects...)
+		if result.Started == 0 || (singleNode.Started > 0 && singleNode.Started < result.Started) {
+			result.Started = singleNode.Started
+		}
+		result.Size += singleNode.Size
+		result.NumObjects += singleNode.NumObjects
+		if singleNode.Finished > 0 {
+			result.Nodes = append(result.Nodes, singleNode)
+			lastFinished = max(lastFinished, singleNode.Finished)
+		} else {
+			result.IncompleteNodesList = append(result.IncompleteNodesList, singleNode)
+		}
+	}
+	for fqdn := range tokenmap {
+		if _, ok := nodes[fqdn]; !ok {
+			result.MissingNodesList = append(result.MissingNodesList, fqdn)
+		}
+	}
+	sort.Strings(result.MissingNodesList)
+	result.CompletedNodes = len(result.Nodes)
+	result.IncompleteNodes = len(result.IncompleteNodesList)
+	result.MissingNodes = len(result.MissingNodesList)
+	if result.IncompleteNodes == 0 && result.MissingNodes == 0 {
+		result.Finished = lastFinished
+	}
+	return result, nil
+}
+
+// Build node backup and return objects from its manifest.
+func buildNode(ctx context.Context, bucket storageBucket, fqdn string, nodeData *nodeIndex) (node, []storageObject, error) {
+	result := node{
+		Finished: nodeData.finished,
+		FQDN:     fqdn,
+		Started:  nodeData.started,
+	}
+	if nodeData.serverVersion != "" {
+		var version serverVersion
+		if err := readJSONObject(ctx, bucket, nodeData.serverVersion, &version); err != nil {
+			return node{}, nil, err
+		}
+		result.ReleaseVersion = version.ReleaseVersion
+		result.ServerType = version.ServerType
+	}
+	var objects []storageObject
+	if nodeData.manifest != "" {
+		var manifest []manifestSection
+		if err := readJSONObject(ctx, bucket, nodeData.manifest, &manifest); err != nil {
+			return node{}, nil, err
+		}
+		for _, section := range manifest {
+			for _, object := range section.Objects {
+				result.Size += object.Size
+				result.NumObjects++
+				objects = append(objects, storageObject{key: object.Path, size: object.Size})
+			}
+		}
+	}
+	return result, objects, nil
+}
+
+func readJSONObject(ctx context.Context, bucket storageBucket, key string, v any) error {
+	data, err := bucket.readObject(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", key, err)
+	}
+	return nil
+}
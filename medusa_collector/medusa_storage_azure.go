@@ -0,0 +1,255 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	azureProvider   = "azure_blobs"
+	azureAPIVersion = "2021-08-06"
+)
+
+// Azure credentials file:
+//
+//	{
+//	  "storage_account": "string",
+//	  "key": "string",
+//	  "sas_token": "string"
+//	}
+//
+// Either shared key or SAS token is required.
+type azureCredentials struct {
+	StorageAccount string `json:"storage_account"`
+	Key            string `json:"key"`
+	SASToken       string `json:"sas_token"`
+}
+
+// Azure Blob Storage provider.
+// Only List Blobs and Get Blob requests are used.
+type azureBucket struct {
+	account   string
+	container string
+	client    *http.Client
+	endpoint  *url.URL
+	// Decoded shared key, nil if SAS token is used.
+	key      []byte
+	sasToken url.Values
+	// For tests.
+	now func() time.Time
+}
+
+// List Blobs response:
+//
+//	<EnumerationResults>
+//	  <Blobs>
+//	    <Blob><Name>string</Name><Properties><Content-Length>number</Content-Length></Properties></Blob>
+//	  </Blobs>
+//	  <NextMarker>string</NextMarker>
+//	</EnumerationResults>
+type azureListResult struct {
+	Blobs []struct {
+		Name       string `xml:"Name"`
+		Properties struct {
+			ContentLength int64 `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+type azureError struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func newAzureBucket(config storageConfig) (*azureBucket, error) {
+	if config.bucketName == "" {
+		return nil, errors.New("bucket_name is not set for azure_blobs")
+	}
+	credentials, err := readAzureCredentials(config.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	b := &azureBucket{
+		account:   credentials.StorageAccount,
+		container: config.bucketName,
+		client:    &http.Client{Timeout: storageTimeout},
+		now:       time.Now,
+	}
+	// For custom host (e.g. Azurite) path-style URL is used: <endpoint>/<account>.
+	endpoint := fmt.Sprintf("https://%s.blob.core.windows.net", b.account)
+	if config.host != "" {
+		endpoint = returnHTTPEndpoint(config.host, config.port, config.secure) + "/" + b.account
+	}
+	if b.endpoint, err = url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid azure endpoint: %w", err)
+	}
+	if credentials.SASToken != "" {
+		if b.sasToken, err = url.ParseQuery(strings.TrimPrefix(credentials.SASToken, "?")); err != nil {
+			return nil, fmt.Errorf("invalid sas_token: %w", err)
+		}
+		return b, nil
+	}
+	if b.key, err = base64.StdEncoding.DecodeString(credentials.Key); err != nil {
+		return nil, fmt.Errorf("invalid azure storage key: %w", err)
+	}
+	return b, nil
+}
+
+// Read Azure credentials from key file.
+// If key file is not set, credentials are taken from environment variables.
+func readAzureCredentials(keyFile string) (azureCredentials, error) {
+	credentials := azureCredentials{
+		StorageAccount: os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		Key:            os.Getenv("AZURE_STORAGE_KEY"),
+		SASToken:       os.Getenv("AZURE_STORAGE_SAS_TOKEN"),
+	}
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return azureCredentials{}, err
+		}
+		credentials = azureCredentials{}
+		if err := json.Unmarshal(data, &credentials); err != nil {
+			return azureCredentials{}, fmt.Errorf("parse %s: %w", keyFile, err)
+		}
+	}
+	if credentials.StorageAccount == "" {
+		return azureCredentials{}, errors.New("azure storage account is not set")
+	}
+	if credentials.Key == "" && credentials.SASToken == "" {
+		return azureCredentials{}, errors.New("azure storage key or sas token is not set")
+	}
+	return credentials, nil
+}
+
+func (b *azureBucket) listObjects(ctx context.Context, prefix string) ([]storageObject, error) {
+	objects := []storageObject{}
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("restype", "container")
+		query.Set("comp", "list")
+		query.Set("prefix", prefix)
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, err := b.do(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+		var result azureListResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("parse List Blobs response: %w", err)
+		}
+		for _, blob := range result.Blobs {
+			objects = append(objects, storageObject{key: blob.Name, size: blob.Properties.ContentLength})
+		}
+		if result.NextMarker == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+	return objects, nil
+}
+
+func (b *azureBucket) readObject(ctx context.Context, key string) ([]byte, error) {
+	return b.do(ctx, key, url.Values{})
+}
+
+// Send GET request to Blob service and return response body.
+func (b *azureBucket) do(ctx context.Context, key string, query url.Values) ([]byte, error) {
+	reqURL := *b.endpoint
+	reqURL.Path = strings.TrimSuffix(reqURL.Path, "/") + "/" + b.container
+	if key != "" {
+		reqURL.Path += "/" + key
+	}
+	for name, values := range b.sasToken {
+		query[name] = values
+	}
+	reqURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Ms-Date", b.now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+	if b.key != nil {
+		signAzureRequest(req, b.account, b.key)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		var azErr azureError
+		if xml.Unmarshal(body, &azErr) == nil && azErr.Code != "" {
+			return nil, fmt.Errorf("azure request %s failed: %s: %s: %s", reqURL.Path, resp.Status, azErr.Code, azErr.Message)
+		}
+		return nil, fmt.Errorf("azure request %s failed: %s", reqURL.Path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Sign request with Shared Key authorization.
+// See https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func signAzureRequest(req *http.Request, account string, key []byte) {
+	signature := base64.StdEncoding.EncodeToString(hmacSHA256(key, azureStringToSign(req, account)))
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", account, signature))
+}
+
+func azureStringToSign(req *http.Request, account string) string {
+	headers := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		// Empty string for zero length.
+		"",
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		// Empty string, because x-ms-date is used.
+		"",
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+	// Canonicalized headers.
+	msHeaders := []string{}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-ms-") {
+			msHeaders = append(msHeaders, name+":"+strings.TrimSpace(strings.Join(values, ",")))
+		}
+	}
+	sort.Strings(msHeaders)
+	// Canonicalized resource.
+	var resource strings.Builder
+	resource.WriteString("/" + account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		resource.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+	return strings.Join(headers, "\n") + "\n" + strings.Join(msHeaders, "\n") + "\n" + resource.String()
+}
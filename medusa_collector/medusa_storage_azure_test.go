@@ -0,0 +1,224 @@
+package medusa_collector
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testAzureAccount = "devstoreaccount1"
+	testAzureKey     = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+	testAzureSAS     = "sv=2021-08-06&ss=b&srt=co&sp=rl&sig=test-signature"
+)
+
+// In-process fake Azure Blob service like Azurite.
+// It uses path-style URLs: /<account>/<container>/<blob>.
+func newFakeAzureServer(t *testing.T, container string, objects map[string]string) *httptest.Server {
+	t.Helper()
+	key, err := base64.StdEncoding.DecodeString(testAzureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !checkFakeAzureAuth(r, key) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><Error><Code>AuthenticationFailed</Code><Message>bad signature</Message></Error>`)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/"+testAzureAccount+"/"+container)
+		if path == "" && r.URL.Query().Get("comp") == "list" {
+			listFakeAzureBlobs(w, r.URL.Query(), objects)
+			return
+		}
+		data, ok := objects[strings.TrimPrefix(path, "/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><Error><Code>BlobNotFound</Code><Message>not found</Message></Error>`)
+			return
+		}
+		fmt.Fprint(w, data)
+	}))
+}
+
+func checkFakeAzureAuth(r *http.Request, key []byte) bool {
+	if r.URL.Query().Get("sig") != "" {
+		return r.URL.Query().Get("sig") == "test-signature"
+	}
+	req := r.Clone(context.Background())
+	authorization := req.Header.Get("Authorization")
+	signAzureRequest(req, testAzureAccount, key)
+	return authorization == req.Header.Get("Authorization")
+}
+
+func listFakeAzureBlobs(w http.ResponseWriter, query url.Values, objects map[string]string) {
+	keys := []string{}
+	for key := range objects {
+		if strings.HasPrefix(key, query.Get("prefix")) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	// Return 4 blobs per page.
+	start, _ := strconv.Atoi(query.Get("marker"))
+	end := min(start+4, len(keys))
+	type blob struct {
+		Name          string `xml:"Name"`
+		ContentLength int64  `xml:"Properties>Content-Length"`
+	}
+	result := struct {
+		XMLName    xml.Name `xml:"EnumerationResults"`
+		Blobs      []blob   `xml:"Blobs>Blob"`
+		NextMarker string   `xml:"NextMarker"`
+	}{}
+	for _, key := range keys[start:end] {
+		result.Blobs = append(result.Blobs, blob{key, int64(len(objects[key]))})
+	}
+	if end < len(keys) {
+		result.NextMarker = strconv.Itoa(end)
+	}
+	data, _ := xml.Marshal(result)
+	w.Write(data)
+}
+
+func TestAzureStringToSign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:10000/devstoreaccount1/backup?restype=container&comp=list&prefix=index%2F", http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Ms-Date", "Thu, 19 Oct 2023 10:40:00 GMT")
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+	want := "GET\n\n\n\n\n\n\n\n\n\n\n\n" +
+		"x-ms-date:Thu, 19 Oct 2023 10:40:00 GMT\n" +
+		"x-ms-version:2021-08-06\n" +
+		"/devstoreaccount1/devstoreaccount1/backup\n" +
+		"comp:list\n" +
+		"prefix:index/\n" +
+		"restype:container"
+	if got := azureStringToSign(req, testAzureAccount); got != want {
+		t.Errorf("\nVariables do not match:\ngot: %q\nwant: %q", got, want)
+	}
+}
+
+func TestAzureBucketListIndexBackups(t *testing.T) {
+	server := newFakeAzureServer(t, "backup", testBackupIndex("prod/"))
+	defer server.Close()
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "credentials.json")
+	sasKeyFile := filepath.Join(dir, "sas_credentials.json")
+	if err := os.WriteFile(keyFile, []byte(fmt.Sprintf(`{"storage_account":%q,"key":%q}`, testAzureAccount, testAzureKey)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sasKeyFile, []byte(fmt.Sprintf(`{"storage_account":%q,"sas_token":"?%s"}`, testAzureAccount, testAzureSAS)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keyFile string
+	}{
+		{"SharedKey", keyFile},
+		{"SASToken", sasKeyFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, err := newStorageBucket(storageConfig{
+				provider:   azureProvider,
+				bucketName: "backup",
+				keyFile:    tt.keyFile,
+				host:       server.URL,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := listIndexBackups(context.Background(), bucket, "prod", logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, testIndexBackups()) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, testIndexBackups())
+			}
+		})
+	}
+	// Request with bad key.
+	bucket, err := newAzureBucket(storageConfig{bucketName: "backup", keyFile: keyFile, host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket.key = []byte("bad")
+	bucket.now = func() time.Time { return time.Date(2023, 10, 19, 0, 0, 0, 0, time.UTC) }
+	if _, err := bucket.readObject(context.Background(), "prod/index/backup_index/full_1/manifest_node1.json"); err == nil ||
+		!strings.Contains(err.Error(), "AuthenticationFailed") {
+		t.Errorf("\nExpected authentication error, got: %v", err)
+	}
+}
+
+func TestReadAzureCredentials(t *testing.T) {
+	dir := t.TempDir()
+	noKeyFile := filepath.Join(dir, "no_key.json")
+	if err := os.WriteFile(noKeyFile, []byte(`{"storage_account":"account"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		keyFile string
+		env     map[string]string
+		want    azureCredentials
+		wantErr bool
+	}{
+		{
+			"Environment",
+			"",
+			map[string]string{"AZURE_STORAGE_ACCOUNT": "account", "AZURE_STORAGE_KEY": "key", "AZURE_STORAGE_SAS_TOKEN": ""},
+			azureCredentials{StorageAccount: "account", Key: "key"},
+			false,
+		},
+		{
+			"NoAccount",
+			"",
+			map[string]string{"AZURE_STORAGE_ACCOUNT": "", "AZURE_STORAGE_KEY": "key"},
+			azureCredentials{},
+			true,
+		},
+		{
+			"NoKey",
+			noKeyFile,
+			nil,
+			azureCredentials{},
+			true,
+		},
+		{
+			"FileNotExist",
+			filepath.Join(dir, "not_exist.json"),
+			nil,
+			azureCredentials{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			got, err := readAzureCredentials(tt.keyFile)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package medusa_collector
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsProvider        = "google_storage"
+	gcsDefaultEndpoint = "https://storage.googleapis.com"
+	gcsDefaultTokenURI = "https://oauth2.googleapis.com/token"
+	gcsReadOnlyScope   = "https://www.googleapis.com/auth/devstorage.read_only"
+	gcsEmulatorEnv     = "STORAGE_EMULATOR_HOST"
+	// Refresh access token a bit earlier than it expires.
+	gcsTokenExpiryDelta = time.Minute
+)
+
+// Service account key file:
+//
+//	{
+//	  "type": "service_account",
+//	  "client_email": "string",
+//	  "private_key_id": "string",
+//	  "private_key": "string",
+//	  "token_uri": "string"
+//	}
+type gcsServiceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// Google Cloud Storage provider.
+// Objects are read via JSON API, service account key is used for authorization.
+type gcsBucket struct {
+	bucket   string
+	client   *http.Client
+	endpoint string
+	// No authorization is used for storage emulator.
+	account    *gcsServiceAccount
+	privateKey *rsa.PrivateKey
+	mu         sync.Mutex
+	token      string
+	expiry     time.Time
+	// For tests.
+	now func() time.Time
+}
+
+//	{
+//	  "items": [{"name": "string", "size": "string"}],
+//	  "nextPageToken": "string"
+//	}
+type gcsListResult struct {
+	Items []struct {
+		Name string `json:"name"`
+		Size string `json:"size"`
+	} `json:"items"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+//	{
+//	  "access_token": "string",
+//	  "expires_in": number
+//	}
+type gcsTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func newGCSBucket(config storageConfig) (*gcsBucket, error) {
+	if config.bucketName == "" {
+		return nil, errors.New("bucket_name is not set for google_storage")
+	}
+	b := &gcsBucket{
+		bucket:   config.bucketName,
+		client:   &http.Client{Timeout: storageTimeout},
+		endpoint: gcsDefaultEndpoint,
+		now:      time.Now,
+	}
+	// The same variable as in Google Cloud client libraries.
+	emulatorHost := os.Getenv(gcsEmulatorEnv)
+	switch {
+	case config.host != "":
+		b.endpoint = returnHTTPEndpoint(config.host, config.port, config.secure)
+	case emulatorHost != "":
+		b.endpoint = returnHTTPEndpoint(emulatorHost, "", false)
+	}
+	if config.keyFile == "" {
+		if emulatorHost == "" {
+			return nil, errors.New("key_file is not set for google_storage")
+		}
+		return b, nil
+	}
+	account, privateKey, err := readGCSServiceAccount(config.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	b.account = account
+	b.privateKey = privateKey
+	return b, nil
+}
+
+// Build endpoint URL from host and port.
+// Host may already contain scheme, in this case 'secure' is ignored.
+func returnHTTPEndpoint(host, port string, secure bool) string {
+	if port != "" {
+		host = host + ":" + port
+	}
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return strings.TrimSuffix(host, "/")
+	}
+	if secure {
+		return "https://" + host
+	}
+	return "http://" + host
+}
+
+func readGCSServiceAccount(keyFile string) (*gcsServiceAccount, *rsa.PrivateKey, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var account gcsServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", keyFile, err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, nil, fmt.Errorf("parse %s: client_email or private_key is empty", keyFile)
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = gcsDefaultTokenURI
+	}
+	privateKey, err := parseRSAPrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", keyFile, err)
+	}
+	return &account, privateKey, nil
+}
+
+// Parse PEM encoded RSA private key in PKCS #8 or PKCS #1 form.
+func parseRSAPrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not RSA key")
+	}
+	return rsaKey, nil
+}
+
+func (b *gcsBucket) listObjects(ctx context.Context, prefix string) ([]storageObject, error) {
+	objects := []storageObject{}
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("prefix", prefix)
+		query.Set("fields", "items(name,size),nextPageToken")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		body, err := b.do(ctx, "/storage/v1/b/"+url.PathEscape(b.bucket)+"/o", query)
+		if err != nil {
+			return nil, err
+		}
+		var result gcsListResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("parse objects list response: %w", err)
+		}
+		for _, item := range result.Items {
+			// Size is uint64 formatted as a string.
+			size, _ := strconv.ParseInt(item.Size, 10, 64)
+			objects = append(objects, storageObject{key: item.Name, size: size})
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+	return objects, nil
+}
+
+func (b *gcsBucket) readObject(ctx context.Context, key string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("alt", "media")
+	return b.do(ctx, "/storage/v1/b/"+url.PathEscape(b.bucket)+"/o/"+url.PathEscape(key), query)
+}
+
+// Send GET request to JSON API and return response body.
+// Path must be already escaped.
+func (b *gcsBucket) do(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.endpoint+path+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if b.account != nil {
+		token, err := b.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, fmt.Errorf("gcs request %s failed: %s: %s", req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Get OAuth 2.0 access token for service account.
+// Token is cached until it expires.
+// See https://developers.google.com/identity/protocols/oauth2/service-account#httprest
+func (b *gcsBucket) accessToken(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if b.token != "" && now.Before(b.expiry) {
+		return b.token, nil
+	}
+	assertion, err := b.signJWT(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gcs token request failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var token gcsTokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("gcs token response has no access_token")
+	}
+	b.token = token.AccessToken
+	b.expiry = now.Add(time.Duration(token.ExpiresIn)*time.Second - gcsTokenExpiryDelta)
+	return b.token, nil
+}
+
+// Create JWT signed with RS256 for OAuth 2.0 token request.
+func (b *gcsBucket) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": b.account.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   b.account.ClientEmail,
+		"scope": gcsReadOnlyScope,
+		"aud":   b.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, b.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
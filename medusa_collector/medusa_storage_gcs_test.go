@@ -0,0 +1,251 @@
+package medusa_collector
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testGCSToken = "test-access-token"
+
+// In-process fake GCS JSON API server with OAuth 2.0 token endpoint.
+// If publicKey is nil, authorization is not checked (like storage emulator).
+func newFakeGCSServer(t *testing.T, bucket string, objects map[string]string, publicKey *rsa.PublicKey, tokenRequests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests.Add(1)
+		if err := checkFakeJWT(r.FormValue("assertion"), publicKey); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"error":"invalid_grant","error_description":%q}`, err.Error())
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":%q,"expires_in":3600,"token_type":"Bearer"}`, testGCSToken)
+	})
+	mux.HandleFunc("GET /storage/v1/b/{bucket}/o", func(w http.ResponseWriter, r *http.Request) {
+		if !checkFakeGCSRequest(w, r, bucket, publicKey) {
+			return
+		}
+		keys := []string{}
+		for key := range objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		// Return 3 objects per page.
+		start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+		end := min(start+3, len(keys))
+		result := map[string]any{}
+		items := []map[string]string{}
+		for _, key := range keys[start:end] {
+			items = append(items, map[string]string{"name": key, "size": strconv.Itoa(len(objects[key]))})
+		}
+		result["items"] = items
+		if end < len(keys) {
+			result["nextPageToken"] = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("GET /storage/v1/b/{bucket}/o/{object}", func(w http.ResponseWriter, r *http.Request) {
+		if !checkFakeGCSRequest(w, r, bucket, publicKey) {
+			return
+		}
+		data, ok := objects[r.PathValue("object")]
+		if !ok || r.URL.Query().Get("alt") != "media" {
+			http.Error(w, `{"error":{"code":404,"message":"No such object"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, data)
+	})
+	return httptest.NewServer(mux)
+}
+
+func checkFakeGCSRequest(w http.ResponseWriter, r *http.Request, bucket string, publicKey *rsa.PublicKey) bool {
+	if r.PathValue("bucket") != bucket {
+		http.Error(w, `{"error":{"code":404,"message":"No such bucket"}}`, http.StatusNotFound)
+		return false
+	}
+	if publicKey != nil && r.Header.Get("Authorization") != "Bearer "+testGCSToken {
+		http.Error(w, `{"error":{"code":401,"message":"Invalid Credentials"}}`, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func checkFakeJWT(assertion string, publicKey *rsa.PublicKey) error {
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid jwt")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], signature); err != nil {
+		return err
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var c map[string]any
+	if err := json.Unmarshal(claims, &c); err != nil {
+		return err
+	}
+	if c["scope"] != gcsReadOnlyScope || c["iss"] != "medusa@example.iam.gserviceaccount.com" {
+		return fmt.Errorf("invalid claims: %v", c)
+	}
+	return nil
+}
+
+// Write service account key file with a new RSA key.
+func writeGCSKeyFile(t *testing.T, dir, tokenURI string) (string, *rsa.PrivateKey) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "medusa@example.iam.gserviceaccount.com",
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      tokenURI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "credentials.json")
+	if err := os.WriteFile(keyFile, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return keyFile, privateKey
+}
+
+func TestGCSBucketListIndexBackups(t *testing.T) {
+	dir := t.TempDir()
+	var tokenRequests atomic.Int32
+	// The key is needed before server start, so the token URI is patched later.
+	keyFile, privateKey := writeGCSKeyFile(t, dir, "")
+	server := newFakeGCSServer(t, "backup", testBackupIndex("prod/"), &privateKey.PublicKey, &tokenRequests)
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket, err := newStorageBucket(storageConfig{
+		provider:   gcsProvider,
+		bucketName: "backup",
+		keyFile:    keyFile,
+		host:       serverURL.Hostname(),
+		port:       serverURL.Port(),
+		secure:     false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket.(*gcsBucket).account.TokenURI = server.URL + "/token"
+	got, err := listIndexBackups(context.Background(), bucket, "prod", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testIndexBackups()) {
+		t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, testIndexBackups())
+	}
+	// Token must be cached.
+	if tokenRequests.Load() != 1 {
+		t.Errorf("\nVariables do not match:\ngot: %d token requests\nwant: %d", tokenRequests.Load(), 1)
+	}
+	if _, err := bucket.readObject(context.Background(), "prod/not_exist"); err == nil ||
+		!strings.Contains(err.Error(), "404") {
+		t.Errorf("\nExpected not found error, got: %v", err)
+	}
+}
+
+func TestGCSBucketEmulator(t *testing.T) {
+	var tokenRequests atomic.Int32
+	server := newFakeGCSServer(t, "backup", testBackupIndex(""), nil, &tokenRequests)
+	defer server.Close()
+	t.Setenv(gcsEmulatorEnv, server.URL)
+	bucket, err := newStorageBucket(storageConfig{provider: gcsProvider, bucketName: "backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := listIndexBackups(context.Background(), bucket, "", logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, testIndexBackups()) || tokenRequests.Load() != 0 {
+		t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, testIndexBackups())
+	}
+}
+
+func TestNewGCSBucketErrors(t *testing.T) {
+	dir := t.TempDir()
+	badKeyFile := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badKeyFile, []byte(`{"client_email":"a","private_key":"not a key"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(gcsEmulatorEnv, "")
+	tests := []struct {
+		name   string
+		config storageConfig
+	}{
+		{"NoBucket", storageConfig{provider: gcsProvider}},
+		{"NoKeyFile", storageConfig{provider: gcsProvider, bucketName: "backup"}},
+		{"KeyFileNotExist", storageConfig{provider: gcsProvider, bucketName: "backup", keyFile: filepath.Join(dir, "not_exist.json")}},
+		{"BadPrivateKey", storageConfig{provider: gcsProvider, bucketName: "backup", keyFile: badKeyFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newGCSBucket(tt.config); err == nil {
+				t.Errorf("\nExpected error, got nil")
+			}
+		})
+	}
+}
+
+func TestReturnHTTPEndpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		host   string
+		port   string
+		secure bool
+		want   string
+	}{
+		{"Secure", "storage.example.com", "", true, "https://storage.example.com"},
+		{"InsecureWithPort", "localhost", "4443", false, "http://localhost:4443"},
+		{"WithScheme", "http://localhost:4443/", "", true, "http://localhost:4443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := returnHTTPEndpoint(tt.host, tt.port, tt.secure); got != tt.want {
+				t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
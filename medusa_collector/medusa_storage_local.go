@@ -0,0 +1,61 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Local storage provider.
+// Medusa stores objects as files in '<base_path>/<bucket_name>' directory.
+type localBucket struct {
+	root string
+}
+
+func newLocalBucket(config storageConfig) (*localBucket, error) {
+	if config.basePath == "" {
+		return nil, errors.New("base_path is not set for local storage")
+	}
+	return &localBucket{root: filepath.Join(config.basePath, config.bucketName)}, nil
+}
+
+func (b *localBucket) listObjects(ctx context.Context, prefix string) ([]storageObject, error) {
+	objects := []storageObject{}
+	err := filepath.WalkDir(filepath.Join(b.root, filepath.FromSlash(prefix)), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, storageObject{key: filepath.ToSlash(rel), size: info.Size()})
+		return nil
+	})
+	// No index directory means there are no backups yet.
+	if errors.Is(err, fs.ErrNotExist) {
+		return []storageObject{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].key < objects[j].key })
+	return objects, nil
+}
+
+func (b *localBucket) readObject(_ context.Context, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(b.root, filepath.FromSlash(key)))
+}
@@ -0,0 +1,124 @@
+package medusa_collector
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Write objects as files to local storage directory.
+func writeLocalObjects(t *testing.T, root string, objects map[string]string) {
+	t.Helper()
+	for key, data := range objects {
+		path := filepath.Join(root, filepath.FromSlash(key))
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalBucket(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalObjects(t, filepath.Join(dir, "backup"), testBackupIndex("prod/"))
+	bucket, err := newLocalBucket(storageConfig{basePath: dir, bucketName: "backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := bucket.listObjects(context.Background(), "prod/index/backup_index/full_1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 11 {
+		t.Errorf("\nVariables do not match:\ngot: %d objects\nwant: %d objects", len(objects), 11)
+	}
+	objects, err = bucket.listObjects(context.Background(), "dev/index/backup_index/")
+	if err != nil || len(objects) != 0 {
+		t.Errorf("\nExpected empty list for absent prefix, got: %v, %v", objects, err)
+	}
+	data, err := bucket.readObject(context.Background(), "prod/index/backup_index/full_1/started_node1_1697711900.timestamp")
+	if err != nil || string(data) != "1697711900" {
+		t.Errorf("\nVariables do not match:\ngot: %s, %v\nwant: %s", data, err, "1697711900")
+	}
+}
+
+func TestStorageSourceLocal(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalObjects(t, filepath.Join(dir, "backup"), testBackupIndex("prod/"))
+	config := filepath.Join(dir, "medusa.ini")
+	configData := fmt.Sprintf("[storage]\nstorage_provider = local\nbase_path = %s\nbucket_name = backup\nprefix = prod\n", dir)
+	if err := os.WriteFile(config, []byte(configData), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		prefix string
+		want   []backup
+	}{
+		{"PrefixFromConfig", "", testIndexBackups()},
+		{"PrefixFromFlag", "prod", testIndexBackups()},
+		{"OtherPrefix", "dev", []backup{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := newStorageSource(config, tt.prefix, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer source.Close()
+			if source.Name() != "storage:local" {
+				t.Errorf("\nVariables do not match:\ngot: %s\nwant: %s", source.Name(), "storage:local")
+			}
+			got, err := source.List(context.Background(), setUpMetricValue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMedusaInfoStorage(t *testing.T) {
+	dir := t.TempDir()
+	writeLocalObjects(t, filepath.Join(dir, "backup"), testBackupIndex(""))
+	config := filepath.Join(dir, "medusa.ini")
+	configData := fmt.Sprintf("[storage]\nstorage_provider = local\nbase_path = %s\nbucket_name = backup\n", dir)
+	if err := os.WriteFile(config, []byte(configData), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		root     string
+		testText string
+	}{
+		{"GetMedusaInfoStorageSuccess", filepath.Join(dir, "backup"), ""},
+		{"GetMedusaInfoStorageError", config, `level=ERROR msg="Get data from Medusa failed" source=storage:local`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetStagingMetrics()
+			out := &bytes.Buffer{}
+			lc := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+			source, err := newStorageSource(config, "", lc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// In error case root is a file instead of directory.
+			source.bucket = &localBucket{root: tt.root}
+			GetMedusaInfo(context.Background(), []Target{{Source: source}}, lc)
+			if !strings.Contains(out.String(), tt.testText) {
+				t.Errorf("\nVariable do not match:\n%s\nwant:\n%s", tt.testText, out.String())
+			}
+		})
+	}
+}
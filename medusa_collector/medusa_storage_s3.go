@@ -0,0 +1,344 @@
+package medusa_collector
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion  = "us-east-1"
+	s3Service        = "s3"
+	s3Algorithm      = "AWS4-HMAC-SHA256"
+	s3TimeFormat     = "20060102T150405Z"
+	s3EmptyHash      = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3PathStyle      = "path"
+	s3VirtualStyle   = "virtual"
+	defaultProfile   = "default"
+	storageTimeout   = 60 * time.Second
+	maxErrorBodySize = 4096
+)
+
+// Medusa storage providers which use S3 API.
+var s3Providers = []string{"s3", "s3_compatible", "s3_rgw", "minio", "ibm_storage"}
+
+type s3Credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// S3 and S3-compatible storage provider.
+// Only ListObjectsV2 and GetObject requests are used.
+type s3Bucket struct {
+	bucket      string
+	client      *http.Client
+	credentials s3Credentials
+	endpoint    *url.URL
+	pathStyle   bool
+	region      string
+	// For tests.
+	now func() time.Time
+}
+
+// ListObjectsV2 response:
+//
+//	<ListBucketResult>
+//	  <IsTruncated>bool</IsTruncated>
+//	  <Contents><Key>string</Key><Size>number</Size></Contents>
+//	  <NextContinuationToken>string</NextContinuationToken>
+//	</ListBucketResult>
+type s3ListBucketResult struct {
+	IsTruncated bool `xml:"IsTruncated"`
+	Contents    []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func isS3Provider(provider string) bool {
+	for _, p := range s3Providers {
+		if provider == p {
+			return true
+		}
+	}
+	return false
+}
+
+func newS3Bucket(config storageConfig) (*s3Bucket, error) {
+	if config.bucketName == "" {
+		return nil, errors.New("bucket_name is not set for s3 storage")
+	}
+	credentials, err := readS3Credentials(config.keyFile, config.apiProfile)
+	if err != nil {
+		return nil, err
+	}
+	region := config.region
+	if region == "" || region == defaultProfile {
+		region = s3DefaultRegion
+	}
+	endpoint, pathStyle, err := returnS3Endpoint(config, region)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Bucket{
+		bucket:      config.bucketName,
+		client:      &http.Client{Timeout: storageTimeout},
+		credentials: credentials,
+		endpoint:    endpoint,
+		pathStyle:   pathStyle,
+		region:      region,
+		now:         time.Now,
+	}, nil
+}
+
+// Get S3 endpoint and addressing style.
+// For custom host path-style addressing is used by default,
+// because most S3-compatible storages (MinIO, Ceph RGW) support only it.
+func returnS3Endpoint(config storageConfig, region string) (*url.URL, bool, error) {
+	scheme := "https"
+	if !config.secure {
+		scheme = "http"
+	}
+	host := config.host
+	pathStyle := config.addressingStyle == s3PathStyle
+	if host == "" {
+		host = fmt.Sprintf("s3.%s.amazonaws.com", region)
+	} else if config.addressingStyle != s3VirtualStyle {
+		pathStyle = true
+	}
+	if config.port != "" {
+		host = host + ":" + config.port
+	}
+	endpoint, err := url.Parse(scheme + "://" + host)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	return endpoint, pathStyle, nil
+}
+
+// Read AWS credentials from key file with profiles:
+//
+//	[default]
+//	aws_access_key_id = string
+//	aws_secret_access_key = string
+//
+// If key file is not set, credentials are taken from environment variables.
+func readS3Credentials(keyFile, profile string) (s3Credentials, error) {
+	if keyFile == "" {
+		credentials := s3Credentials{
+			accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if credentials.accessKeyID == "" || credentials.secretAccessKey == "" {
+			return s3Credentials{}, errors.New("key_file is not set and AWS credentials are not found in environment")
+		}
+		return credentials, nil
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return s3Credentials{}, err
+	}
+	sections, err := parseMedusaConfig(string(data))
+	if err != nil {
+		return s3Credentials{}, fmt.Errorf("parse %s: %w", keyFile, err)
+	}
+	if profile == "" {
+		profile = defaultProfile
+	}
+	section, ok := sections[strings.ToLower(profile)]
+	if !ok {
+		return s3Credentials{}, fmt.Errorf("parse %s: no [%s] profile", keyFile, profile)
+	}
+	credentials := s3Credentials{
+		accessKeyID:     section["aws_access_key_id"],
+		secretAccessKey: section["aws_secret_access_key"],
+		sessionToken:    section["aws_session_token"],
+	}
+	if credentials.accessKeyID == "" || credentials.secretAccessKey == "" {
+		return s3Credentials{}, fmt.Errorf("parse %s: no credentials in [%s] profile", keyFile, profile)
+	}
+	return credentials, nil
+}
+
+func (b *s3Bucket) listObjects(ctx context.Context, prefix string) ([]storageObject, error) {
+	objects := []storageObject{}
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		body, err := b.do(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListBucketResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("parse ListObjectsV2 response: %w", err)
+		}
+		for _, content := range result.Contents {
+			objects = append(objects, storageObject{key: content.Key, size: content.Size})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		continuationToken = result.NextContinuationToken
+	}
+	return objects, nil
+}
+
+func (b *s3Bucket) readObject(ctx context.Context, key string) ([]byte, error) {
+	return b.do(ctx, key, nil)
+}
+
+// Send signed GET request to S3 and return response body.
+func (b *s3Bucket) do(ctx context.Context, key string, query url.Values) ([]byte, error) {
+	reqURL := *b.endpoint
+	switch {
+	case b.pathStyle && key == "":
+		reqURL.Path = "/" + b.bucket
+	case b.pathStyle:
+		reqURL.Path = "/" + b.bucket + "/" + key
+	default:
+		reqURL.Host = b.bucket + "." + reqURL.Host
+		reqURL.Path = "/" + key
+	}
+	reqURL.RawPath = s3EncodePath(reqURL.Path)
+	reqURL.RawQuery = s3EncodeQuery(query)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", s3EmptyHash)
+	req.Header.Set("X-Amz-Date", b.now().UTC().Format(s3TimeFormat))
+	if b.credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.credentials.sessionToken)
+	}
+	signS3Request(req, b.credentials, b.region, s3EmptyHash)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		var s3Err s3Error
+		if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fmt.Errorf("s3 request %s failed: %s: %s: %s", reqURL.Path, resp.Status, s3Err.Code, s3Err.Message)
+		}
+		return nil, fmt.Errorf("s3 request %s failed: %s", reqURL.Path, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Sign request with AWS Signature Version 4.
+// All headers which are already set on the request are signed.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signS3Request(req *http.Request, credentials s3Credentials, region, payloadHash string) {
+	amzDate := req.Header.Get("X-Amz-Date")
+	scope := strings.Join([]string{amzDate[:8], region, s3Service, "aws4_request"}, "/")
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+	signingKey := hmacSHA256([]byte("AWS4"+credentials.secretAccessKey), amzDate[:8])
+	for _, part := range []string{region, s3Service, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, credentials.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// URI-encode path as required by SigV4: every byte except unreserved characters and '/'.
+func s3EncodePath(path string) string {
+	return s3Escape(path, false)
+}
+
+// URI-encode query parameters sorted by name as required by SigV4.
+func s3EncodeQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, s3Escape(key, true)+"="+s3Escape(query.Get(key), true))
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3Escape(value string, encodeSlash bool) string {
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			result.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			result.WriteByte(c)
+		default:
+			fmt.Fprintf(&result, "%%%02X", c)
+		}
+	}
+	return result.String()
+}
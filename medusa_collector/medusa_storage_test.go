@@ -0,0 +1,225 @@
+package medusa_collector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// In-memory storage for tests.
+type memBucket struct {
+	objects map[string]string
+}
+
+func (b *memBucket) listObjects(_ context.Context, prefix string) ([]storageObject, error) {
+	objects := []storageObject{}
+	for key, data := range b.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, storageObject{key: key, size: int64(len(data))})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].key < objects[j].key })
+	return objects, nil
+}
+
+func (b *memBucket) readObject(_ context.Context, key string) ([]byte, error) {
+	data, ok := b.objects[key]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+	return []byte(data), nil
+}
+
+// Medusa backup index for tests:
+//   - full backup on two nodes,
+//   - differential backup with one incomplete and one missing node.
+func testBackupIndex(prefixPath string) map[string]string {
+	tokenmap := `{"node1":{"tokens":[1],"is_up":true},"node2":{"tokens":[2],"is_up":true},"node3":{"tokens":[3],"is_up":true}}`
+	tokenmapFull := `{"node1":{"tokens":[1],"is_up":true},"node2":{"tokens":[2],"is_up":true}}`
+	// Full backup files are stored in backup directory, differential backup files are shared.
+	manifest := func(path string) string {
+		return `[{"keyspace":"ks","columnfamily":"tbl","objects":[{"path":"` + path + `/a","MD5":"x","size":100},{"path":"` + path + `/b","MD5":"y","size":24}]}]`
+	}
+	version := `{"server_type":"cassandra","release_version":"5.0.4"}`
+	index := prefixPath + "index/backup_index/"
+	return map[string]string{
+		index + "full_1/tokenmap_node1.json":                 tokenmapFull,
+		index + "full_1/tokenmap_node2.json":                 tokenmapFull,
+		index + "full_1/manifest_node1.json":                 manifest("node1/full_1/data/ks/tbl"),
+		index + "full_1/manifest_node2.json":                 manifest("node2/full_1/data/ks/tbl"),
+		index + "full_1/schema_node1.cql":                    "",
+		index + "full_1/server_version_node1.json":           version,
+		index + "full_1/server_version_node2.json":           version,
+		index + "full_1/started_node1_1697711900.timestamp":  "1697711900",
+		index + "full_1/started_node2_1697711910.timestamp":  "1697711910",
+		index + "full_1/finished_node1_1697712000.timestamp": "1697712000",
+		index + "full_1/finished_node2_1697712010.timestamp": "1697712010",
+		index + "diff_1/tokenmap_node1.json":                 tokenmap,
+		index + "diff_1/tokenmap_node2.json":                 tokenmap,
+		index + "diff_1/manifest_node1.json":                 manifest("node1/data/ks/tbl"),
+		index + "diff_1/differential_node1":                  "",
+		index + "diff_1/differential_node2":                  "",
+		index + "diff_1/server_version_node1.json":           version,
+		index + "diff_1/started_node1_1697722000.timestamp":  "1697722000",
+		index + "diff_1/started_node2_1697722005.timestamp":  "1697722005",
+		index + "diff_1/finished_node1_1697722100.timestamp": "1697722100",
+		index + "unknown_file":                               "",
+	}
+}
+
+func testIndexBackups() []backup {
+	return []backup{
+		{
+			BackupType:          fullLabel,
+			CompletedNodes:      2,
+			Finished:            1697712010,
+			IncompleteNodes:     0,
+			IncompleteNodesList: []node{},
+			MissingNodes:        0,
+			MissingNodesList:    []string{},
+			Name:                "full_1",
+			Nodes: []node{
+				{Finished: 1697712000, FQDN: "node1", NumObjects: 2, ReleaseVersion: "5.0.4", ServerType: "cassandra", Size: 124, Started: 1697711900},
+				{Finished: 1697712010, FQDN: "node2", NumObjects: 2, ReleaseVersion: "5.0.4", ServerType: "cassandra", Size: 124, Started: 1697711910},
+			},
+			NumObjects: 4,
+			Size:       248,
+			Started:    1697711900,
+			objects: []storageObject{
+				{key: "node1/full_1/data/ks/tbl/a", size: 100},
+				{key: "node1/full_1/data/ks/tbl/b", size: 24},
+				{key: "node2/full_1/data/ks/tbl/a", size: 100},
+				{key: "node2/full_1/data/ks/tbl/b", size: 24},
+			},
+		},
+		{
+			BackupType:      differentialLabel,
+			CompletedNodes:  1,
+			Finished:        0,
+			IncompleteNodes: 1,
+			IncompleteNodesList: []node{
+				{Finished: 0, FQDN: "node2", Started: 1697722005},
+			},
+			MissingNodes:     1,
+			MissingNodesList: []string{"node3"},
+			Name:             "diff_1",
+			Nodes: []node{
+				{Finished: 1697722100, FQDN: "node1", NumObjects: 2, ReleaseVersion: "5.0.4", ServerType: "cassandra", Size: 124, Started: 1697722000},
+			},
+			NumObjects: 2,
+			Size:       124,
+			Started:    1697722000,
+			objects: []storageObject{
+				{key: "node1/data/ks/tbl/a", size: 100},
+				{key: "node1/data/ks/tbl/b", size: 24},
+			},
+		},
+	}
+}
+
+func TestListIndexBackups(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects map[string]string
+		prefix  string
+		want    []backup
+		wantErr bool
+	}{
+		{
+			name:    "NoPrefix",
+			objects: testBackupIndex(""),
+			prefix:  "",
+			want:    testIndexBackups(),
+			wantErr: false,
+		},
+		{
+			name:    "WithPrefix",
+			objects: testBackupIndex("prod/"),
+			prefix:  "prod",
+			want:    testIndexBackups(),
+			wantErr: false,
+		},
+		{
+			name:    "OtherPrefix",
+			objects: testBackupIndex("prod/"),
+			prefix:  "dev",
+			want:    []backup{},
+			wantErr: false,
+		},
+		{
+			name: "InvalidManifest",
+			objects: map[string]string{
+				"index/backup_index/full_1/manifest_node1.json":                 "{invalid json}",
+				"index/backup_index/full_1/started_node1_1697711900.timestamp":  "",
+				"index/backup_index/full_1/finished_node1_1697712000.timestamp": "",
+			},
+			prefix:  "",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listIndexBackups(context.Background(), &memBucket{objects: tt.objects}, tt.prefix, logger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIndexFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		wantFQDN string
+		wantOK   bool
+	}{
+		{"Tokenmap", "tokenmap_node1.example.com.json", "node1.example.com", true},
+		{"Manifest", "manifest_node1.example.com.json", "node1.example.com", true},
+		{"Schema", "schema_node1.example.com.cql", "node1.example.com", true},
+		{"ServerVersion", "server_version_node1.example.com.json", "node1.example.com", true},
+		{"Differential", "differential_node1.example.com", "node1.example.com", true},
+		{"Started", "started_node_1_1697711900.timestamp", "node_1", true},
+		{"Finished", "finished_node1.example.com_1697712000.timestamp", "node1.example.com", true},
+		{"BadTimestamp", "finished_node1_abc.timestamp", "", false},
+		{"NoTimestamp", "started_node1.timestamp", "", false},
+		{"BadExtension", "manifest_node1.txt", "", false},
+		{"Unknown", "unknown_file", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFQDN, gotOK := parseIndexFileName(tt.fileName)
+			if gotFQDN != tt.wantFQDN || gotOK != tt.wantOK {
+				t.Errorf("\nVariables do not match:\ngot: %s, %v\nwant: %s, %v", gotFQDN, gotOK, tt.wantFQDN, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewStorageBucket(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  storageConfig
+		wantErr bool
+	}{
+		{"Local", storageConfig{provider: localProvider, basePath: "/tmp", bucketName: "backup"}, false},
+		{"LocalNoBasePath", storageConfig{provider: localProvider, bucketName: "backup"}, true},
+		{"Unsupported", storageConfig{provider: "unknown"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newStorageBucket(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nError expectation does not match:\ngot error: %v\nwant error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
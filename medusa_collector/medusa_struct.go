@@ -27,6 +27,8 @@ type backup struct {
 	NumObjects          int64    `json:"num_objects"`
 	Size                int64    `json:"size"`
 	Started             int64    `json:"started"`
+	// Objects from node manifests, only known for storage source.
+	objects []storageObject
 }
 
 //	"nodes": [{
@@ -46,4 +48,6 @@ type node struct {
 	ServerType     string `json:"server_type"`
 	Size           int64  `json:"size"`
 	Started        int64  `json:"started"`
+	// Node is finished, but its finish time is unknown, e.g. for gRPC source.
+	finishedWithoutTime bool
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -11,6 +12,7 @@ import (
 	kingpin "github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	version_collector "github.com/prometheus/client_golang/prometheus/collectors/version"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
 	"github.com/prometheus/common/version"
@@ -27,17 +29,101 @@ func main() {
 			"Path under which to expose metrics.",
 		).Default("/metrics").String()
 		webAdditionalToolkitFlags = kingpinflag.AddFlags(kingpin.CommandLine, ":19500")
-		collectionInterval        = kingpin.Flag(
+		webEnableLifecycle        = kingpin.Flag(
+			"web.enable-lifecycle",
+			"Enable HTTP POST requests to '/-/reload' for reload of exporter configuration and to '/-/collect' for immediate collection.",
+		).Default("false").Bool()
+		webShutdownTimeout = kingpin.Flag(
+			"web.shutdown-timeout",
+			"Max time to wait for in-flight requests and collection on shutdown.",
+		).Default("30s").Duration()
+		configFile = kingpin.Flag(
+			"config.file",
+			"Path to exporter configuration file in YAML format, flags set in command line override values from file.",
+		).Default("").String()
+		collectionInterval = kingpin.Flag(
 			"collect.interval",
 			"Collecting metrics interval in seconds.",
 		).Default("600").Int()
+		collectSchedule = kingpin.Flag(
+			"collect.schedule",
+			"Cron expression for collecting metrics in 'interval' mode, replaces 'collect.interval'.",
+		).Default("").String()
+		collectJitter = kingpin.Flag(
+			"collect.jitter",
+			"Max random delay added to each scheduled collection in 'interval' mode.",
+		).Default("0s").Duration()
+		collectMode = kingpin.Flag(
+			"collect.mode",
+			"Collecting metrics mode: 'interval' gets data in background every 'collect.interval', 'scrape' gets data on scrape.",
+		).Default(medusa_collector.IntervalMode).Enum(medusa_collector.IntervalMode, medusa_collector.ScrapeMode)
+		collectMinInterval = kingpin.Flag(
+			"collect.min-interval",
+			"Min interval between getting data in 'scrape' mode, metrics from cache are returned for more frequent scrapes.",
+		).Default("30s").Duration()
+		collectKeepLastGood = kingpin.Flag(
+			"collect.keep-last-good",
+			"Keep metrics from the last successful collection when getting data from Medusa fails.",
+		).Default("false").Bool()
+		collectReadyMaxAge = kingpin.Flag(
+			"collect.ready-max-age",
+			"Max age of metrics from the last collection for '/-/ready' endpoint, 0 disables the check.",
+		).Default("30m").Duration()
+		collectConcurrency = kingpin.Flag(
+			"collect.concurrency",
+			"Max number of targets (clusters and prefixes) for which data is collected concurrently.",
+		).Default("4").Int()
 		medusaCustomConfig = kingpin.Flag(
 			"medusa.config-file",
-			"Full path to Medusa configuration file.",
-		).Default("").String()
+			"Full path to Medusa configuration file in '[name=]path' format, name is used as cluster label. Flag can be repeated to collect metrics for several clusters.",
+		).Strings()
 		medusaPrefix = kingpin.Flag(
 			"medusa.prefix",
-			"Prefix for shared storage.",
+			"Prefix for shared storage. Flag can be repeated to collect metrics for several prefixes.",
+		).Strings()
+		medusaSource = kingpin.Flag(
+			"medusa.source",
+			"Source of backup data: 'cli' runs medusa command, 'storage' reads backup index from shared storage, 'grpc' requests Medusa gRPC server.",
+		).Default(medusa_collector.CLISource).Enum(medusa_collector.CLISource, medusa_collector.StorageSource, medusa_collector.GRPCSource)
+		medusaTimeout = kingpin.Flag(
+			"medusa.timeout",
+			"Timeout for getting data from Medusa, 0 disables timeout.",
+		).Default("5m").Duration()
+		medusaRetries = kingpin.Flag(
+			"medusa.retries",
+			"Number of retries for failed getting data from Medusa, 0 disables retries.",
+		).Default("2").Int()
+		medusaRetryBackoff = kingpin.Flag(
+			"medusa.retry-backoff",
+			"Initial delay between retries, it's doubled for each next retry.",
+		).Default("5s").Duration()
+		medusaRetryMaxBackoff = kingpin.Flag(
+			"medusa.retry-max-backoff",
+			"Max delay between retries.",
+		).Default("1m").Duration()
+		medusaGRPCAddress = kingpin.Flag(
+			"medusa.grpc-address",
+			"Medusa gRPC server address in host:port format.",
+		).Default("localhost:50051").String()
+		medusaGRPCTLS = kingpin.Flag(
+			"medusa.grpc-tls",
+			"Use TLS for connection to Medusa gRPC server.",
+		).Default("false").Bool()
+		medusaGRPCTLSCAFile = kingpin.Flag(
+			"medusa.grpc-tls-ca-file",
+			"Full path to CA certificate for Medusa gRPC server verification.",
+		).Default("").String()
+		medusaGRPCTLSCertFile = kingpin.Flag(
+			"medusa.grpc-tls-cert-file",
+			"Full path to client certificate for Medusa gRPC server.",
+		).Default("").String()
+		medusaGRPCTLSKeyFile = kingpin.Flag(
+			"medusa.grpc-tls-key-file",
+			"Full path to client key for Medusa gRPC server.",
+		).Default("").String()
+		medusaGRPCTLSServerName = kingpin.Flag(
+			"medusa.grpc-tls-server-name",
+			"Server name for Medusa gRPC server certificate verification.",
 		).Default("").String()
 	)
 	// Set logger config.
@@ -55,13 +141,24 @@ func main() {
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 	// Set logger.
 	logger := promslog.New(promslogConfig)
+	// Context is canceled on shutdown, which stops collection
+	// and kills running medusa processes.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	// Method invoked upon seeing signal.
+	// The second signal forces exit without waiting for shutdown.
 	go func(logger *slog.Logger) {
 		s := <-sigs
 		logger.Warn(
 			"Stopping exporter",
 			"name", filepath.Base(os.Args[0]),
 			"signal", s)
+		cancel()
+		s = <-sigs
+		logger.Error(
+			"Forced exit of exporter",
+			"name", filepath.Base(os.Args[0]),
+			"signal", s)
 		os.Exit(1)
 	}(logger)
 	logger.Info(
@@ -69,18 +166,85 @@ func main() {
 		"name", filepath.Base(os.Args[0]),
 		"version", version.Info())
 	logger.Info("Build context", "build_context", version.BuildContext())
-	if *medusaCustomConfig != "" {
-		logger.Info(
-			"Custom Medusa configuration file",
-			"file", *medusaCustomConfig)
+	clusters, err := medusa_collector.ParseClusterConfigs(*medusaCustomConfig)
+	if err != nil {
+		logger.Error("Invalid Medusa configuration file", "err", err)
+		os.Exit(1)
 	}
-	if *medusaPrefix != "" {
-		logger.Info(
-			"Collecting metrics for specific prefix in shared storage",
-			"prefix", *medusaPrefix)
+	prefixes := *medusaPrefix
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	flagConfig := medusa_collector.ExporterConfig{
+		Targets: make([]medusa_collector.TargetConfig, 0, len(clusters)*len(prefixes)),
+		Collection: medusa_collector.CollectionConfig{
+			Mode:            *collectMode,
+			Interval:        model.Duration(time.Duration(*collectionInterval) * time.Second),
+			MinInterval:     model.Duration(*collectMinInterval),
+			Concurrency:     *collectConcurrency,
+			KeepLastGood:    *collectKeepLastGood,
+			Timeout:         model.Duration(*medusaTimeout),
+			Retries:         *medusaRetries,
+			RetryBackoff:    model.Duration(*medusaRetryBackoff),
+			RetryMaxBackoff: model.Duration(*medusaRetryMaxBackoff),
+			ReadyMaxAge:     model.Duration(*collectReadyMaxAge),
+			Schedule:        *collectSchedule,
+			Jitter:          model.Duration(*collectJitter),
+		},
+	}
+	for _, cluster := range clusters {
+		for _, prefix := range prefixes {
+			flagConfig.Targets = append(flagConfig.Targets, medusa_collector.TargetConfig{
+				Cluster:    cluster.Name,
+				ConfigFile: cluster.ConfigFile,
+				Prefix:     prefix,
+				Source:     *medusaSource,
+				GRPC: medusa_collector.GRPCConfig{
+					Address:    *medusaGRPCAddress,
+					TLS:        *medusaGRPCTLS,
+					CAFile:     *medusaGRPCTLSCAFile,
+					CertFile:   *medusaGRPCTLSCertFile,
+					KeyFile:    *medusaGRPCTLSKeyFile,
+					ServerName: *medusaGRPCTLSServerName,
+				},
+			})
+		}
+	}
+	setFlags := setByUserFlags(os.Args[1:])
+	// Configuration is loaded on start and on each reload.
+	// Flags set in command line override values from file.
+	loadConfig := func() (medusa_collector.ExporterConfig, error) {
+		config := flagConfig
+		if *configFile == "" {
+			return config, nil
+		}
+		logger.Info("Exporter configuration file", "file", *configFile)
+		if err := medusa_collector.LoadConfig(*configFile, &config); err != nil {
+			return config, err
+		}
+		overrideConfig(&config, flagConfig, setFlags)
+		return config, nil
 	}
+	manager := medusa_collector.NewConfigManager(loadConfig, logger)
+	if err := manager.Reload(); err != nil {
+		// Error is logged by manager.
+		os.Exit(1)
+	}
+	// Reload configuration on SIGHUP.
+	hups := make(chan os.Signal, 1)
+	signal.Notify(hups, syscall.SIGHUP)
+	go func(logger *slog.Logger) {
+		for range hups {
+			logger.Info("Reloading exporter configuration", "signal", syscall.SIGHUP)
+			// Error is logged by manager.
+			_ = manager.Reload()
+		}
+	}(logger)
+	collection := manager.Collection()
 	// Setup parameters for exporter.
 	medusa_collector.SetPromPortAndPath(*webAdditionalToolkitFlags, *webPath)
+	medusa_collector.SetConfigManager(manager)
+	medusa_collector.SetEnableLifecycle(*webEnableLifecycle)
 	logger.Info(
 		"Use exporter parameters",
 		"endpoint", *webPath,
@@ -88,16 +252,116 @@ func main() {
 	)
 	// Exporter build info metric
 	prometheus.MustRegister(version_collector.NewCollector(exporterName))
+	// Closed when background collection is stopped.
+	collectDone := make(chan struct{})
+	if collection.Mode == medusa_collector.ScrapeMode {
+		logger.Info(
+			"Getting data from Medusa on scrape",
+			"min_interval", collection.MinInterval)
+		prometheus.MustRegister(medusa_collector.NewScrapeCollector(ctx, manager, logger))
+		// Data is collected by scrapes.
+		close(collectDone)
+	} else {
+		prometheus.MustRegister(medusa_collector.SnapshotCollector())
+		loop := medusa_collector.NewCollectionLoop(manager, logger)
+		medusa_collector.SetCollectionLoop(loop)
+		go func() {
+			defer close(collectDone)
+			loop.Run(ctx)
+		}()
+	}
+	server, err := medusa_collector.NewPromEndpoint(ctx, version.Info(), logger)
+	if err != nil {
+		logger.Error("Create web endpoint failed", "err", err)
+		os.Exit(1)
+	}
 	// Start web server.
-	medusa_collector.StartPromEndpoint(version.Info(), logger)
-	for {
-		// Get information form Medusa and set metrics.
-		medusa_collector.GetMedusaInfo(
-			*medusaCustomConfig,
-			*medusaPrefix,
-			logger,
-		)
-		// Sleep for 'collection.interval' seconds.
-		time.Sleep(time.Duration(*collectionInterval) * time.Second)
+	serverErrs := make(chan error, 1)
+	go func() {
+		serverErrs <- medusa_collector.ServePromEndpoint(server, logger)
+	}()
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+	case err := <-serverErrs:
+		logger.Error("Run web endpoint failed", "err", err)
+		exitCode = 1
+		cancel()
+	}
+	// In-flight requests are finished and collection is stopped
+	// within shutdown timeout.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), *webShutdownTimeout)
+	defer shutdownCancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Shutdown web endpoint failed", "err", err)
+	}
+	select {
+	case <-collectDone:
+	case <-shutdownCtx.Done():
+		logger.Warn("Collection isn't stopped within shutdown timeout", "timeout", *webShutdownTimeout)
+	}
+	manager.Close()
+	logger.Info("Exporter stopped")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// Flags which set targets.
+// If one of them is set in command line, targets from configuration file are not used.
+var targetFlags = []string{
+	"medusa.config-file",
+	"medusa.prefix",
+	"medusa.source",
+	"medusa.grpc-address",
+	"medusa.grpc-tls",
+	"medusa.grpc-tls-ca-file",
+	"medusa.grpc-tls-cert-file",
+	"medusa.grpc-tls-key-file",
+	"medusa.grpc-tls-server-name",
+}
+
+// Override values from configuration file by flags set in command line.
+func overrideConfig(config *medusa_collector.ExporterConfig, flagConfig medusa_collector.ExporterConfig, setFlags map[string]bool) {
+	for _, name := range targetFlags {
+		if setFlags[name] {
+			config.Targets = flagConfig.Targets
+			break
+		}
+	}
+	collection, flagCollection := &config.Collection, flagConfig.Collection
+	overrides := map[string]func(){
+		"collect.mode":             func() { collection.Mode = flagCollection.Mode },
+		"collect.interval":         func() { collection.Interval = flagCollection.Interval },
+		"collect.min-interval":     func() { collection.MinInterval = flagCollection.MinInterval },
+		"collect.concurrency":      func() { collection.Concurrency = flagCollection.Concurrency },
+		"collect.keep-last-good":   func() { collection.KeepLastGood = flagCollection.KeepLastGood },
+		"collect.ready-max-age":    func() { collection.ReadyMaxAge = flagCollection.ReadyMaxAge },
+		"collect.schedule":         func() { collection.Schedule = flagCollection.Schedule },
+		"collect.jitter":           func() { collection.Jitter = flagCollection.Jitter },
+		"medusa.timeout":           func() { collection.Timeout = flagCollection.Timeout },
+		"medusa.retries":           func() { collection.Retries = flagCollection.Retries },
+		"medusa.retry-backoff":     func() { collection.RetryBackoff = flagCollection.RetryBackoff },
+		"medusa.retry-max-backoff": func() { collection.RetryMaxBackoff = flagCollection.RetryMaxBackoff },
+	}
+	for name, override := range overrides {
+		if setFlags[name] {
+			override()
+		}
+	}
+}
+
+// Return names of flags set in command line.
+func setByUserFlags(args []string) map[string]bool {
+	setFlags := make(map[string]bool)
+	parseContext, err := kingpin.CommandLine.ParseContext(args)
+	if err != nil {
+		return setFlags
+	}
+	for _, element := range parseContext.Elements {
+		if flagClause, ok := element.Clause.(*kingpin.FlagClause); ok {
+			setFlags[flagClause.Model().Name] = true
+		}
 	}
+	return setFlags
 }
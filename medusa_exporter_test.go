@@ -7,9 +7,13 @@ import (
 	"math/big"
 	"net/http"
 	"os"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/woblerr/medusa_exporter/medusa_collector"
 )
 
 func TestMain(t *testing.T) {
@@ -49,3 +53,65 @@ func TestMain(t *testing.T) {
 		})
 	}
 }
+
+func TestOverrideConfig(t *testing.T) {
+	fileConfig := medusa_collector.ExporterConfig{
+		Targets: []medusa_collector.TargetConfig{{Cluster: "prod", Source: medusa_collector.StorageSource}},
+		Collection: medusa_collector.CollectionConfig{
+			Mode:        medusa_collector.ScrapeMode,
+			Interval:    model.Duration(time.Minute),
+			Retries:     5,
+			ReadyMaxAge: model.Duration(time.Hour),
+			Schedule:    "*/5 1-3 * * *",
+		},
+	}
+	flagConfig := medusa_collector.ExporterConfig{
+		Targets: []medusa_collector.TargetConfig{{Source: medusa_collector.CLISource}},
+		Collection: medusa_collector.CollectionConfig{
+			Mode:        medusa_collector.IntervalMode,
+			Interval:    model.Duration(10 * time.Minute),
+			Retries:     2,
+			ReadyMaxAge: model.Duration(30 * time.Minute),
+			Jitter:      model.Duration(time.Minute),
+		},
+	}
+	tests := []struct {
+		name     string
+		setFlags map[string]bool
+		want     medusa_collector.ExporterConfig
+	}{
+		{"NoFlags", map[string]bool{}, fileConfig},
+		{
+			"CollectionFlags",
+			map[string]bool{"collect.interval": true, "medusa.retries": true, "collect.ready-max-age": true, "collect.jitter": true},
+			medusa_collector.ExporterConfig{
+				Targets: fileConfig.Targets,
+				Collection: medusa_collector.CollectionConfig{
+					Mode:        medusa_collector.ScrapeMode,
+					Interval:    model.Duration(10 * time.Minute),
+					Retries:     2,
+					ReadyMaxAge: model.Duration(30 * time.Minute),
+					Schedule:    "*/5 1-3 * * *",
+					Jitter:      model.Duration(time.Minute),
+				},
+			},
+		},
+		{
+			"TargetFlags",
+			map[string]bool{"medusa.prefix": true},
+			medusa_collector.ExporterConfig{
+				Targets:    flagConfig.Targets,
+				Collection: fileConfig.Collection,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fileConfig
+			overrideConfig(&got, flagConfig, tt.setFlags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("\nVariables do not match:\ngot: %+v\nwant: %+v", got, tt.want)
+			}
+		})
+	}
+}